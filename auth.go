@@ -0,0 +1,208 @@
+package fusebill
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DefaultSessionTTL is how long a private API session is assumed valid after login
+const DefaultSessionTTL = 20 * time.Minute
+
+// sessionRefreshMargin is how long before its expiry a session is renewed
+const sessionRefreshMargin = time.Minute
+
+// AuthScheme is the scheme of the Authorization header sent with the token
+type AuthScheme string
+
+const (
+	AuthBasic  AuthScheme = "Basic"
+	AuthBearer AuthScheme = "Bearer"
+)
+
+// NewBasicToken returns the base64 encoded "username:password" token expected by the Basic scheme
+func NewBasicToken(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// authorization returns the Authorization header value for the token
+func (c Credentials) authorization() string {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = AuthBasic
+	}
+
+	token := c.Token
+	if token == "" && scheme == AuthBasic {
+		token = NewBasicToken(c.Username, c.Password)
+	}
+
+	return string(scheme) + " " + token
+}
+
+// TokenRefresher returns a fresh API token once the current one is rejected
+type TokenRefresher func(ctx context.Context) (string, error)
+
+// canReauthenticate reports whether a 401 can be recovered from
+func (f *Fusebill) canReauthenticate() bool {
+	return f.cookieJar != nil || f.RefreshToken != nil
+}
+
+// isAuthRejected reports whether the response means the credentials or session were
+// rejected. For private clients a redirect, which is only seen with WithNoRedirect,
+// or an HTML page is the login page of an expired session. A 304 answers a conditional request.
+func (f *Fusebill) isAuthRejected(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode == http.StatusNotModified || f.cookieJar == nil {
+		return false
+	}
+
+	return resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest || isLoginPage(resp)
+}
+
+// isLoginPage reports whether a successful response is an HTML page rather than the JSON asked for
+func isLoginPage(resp *http.Response) bool {
+	return isSuccess(resp.StatusCode) && mediaType(resp.Header) == "text/html"
+}
+
+// generation returns the current authentication generation
+func (f *Fusebill) generation() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.authGeneration
+}
+
+// SetToken replaces Credentials.Token, it is safe to call while requests are in flight.
+// A request rejected with the old token is sent again with the new one.
+func (f *Fusebill) SetToken(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Credentials.Token = token
+	f.authGeneration++
+}
+
+// authorization returns the Authorization header value for the current credentials
+func (f *Fusebill) authorization() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Credentials.authorization()
+}
+
+// authCall is an authentication in progress, shared by every caller waiting for it
+type authCall struct {
+	done chan struct{}
+	err  error
+}
+
+// reauthenticate logs in again (private clients) or refreshes the token, unless that
+// already happened, or SetToken replaced the token, since the caller observed generation.
+// Concurrent callers share a single attempt and all get its error, so a failing login is
+// not repeated by every waiting goroutine. The attempt is detached from the cancellation
+// of ctx and bounded by Client.Timeout, a caller giving up only stops its own wait.
+func (f *Fusebill) reauthenticate(ctx context.Context, generation uint64) error {
+	f.mu.Lock()
+	if f.authGeneration != generation {
+		f.mu.Unlock()
+		return nil
+	}
+	if !f.canReauthenticate() {
+		f.mu.Unlock()
+		return errors.New("cookie jar or RefreshToken should be set to re-authenticate")
+	}
+
+	call := f.authCall
+	if call == nil {
+		call = &authCall{done: make(chan struct{})}
+		f.authCall = call
+		go f.authenticate(context.WithoutCancel(ctx), call)
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// authenticate runs the shared attempt of call and publishes its result
+func (f *Fusebill) authenticate(ctx context.Context, call *authCall) {
+	var token string
+	var err error
+	if f.cookieJar != nil {
+		err = f.login(ctx)
+	} else {
+		token, err = f.RefreshToken(ctx)
+	}
+
+	f.mu.Lock()
+	if err == nil {
+		f.authGeneration++
+		if f.cookieJar != nil {
+			f.sessionExpires = time.Now().Add(f.sessionTTL())
+		} else {
+			f.Credentials.Token = token
+		}
+	}
+	f.authCall = nil
+	f.mu.Unlock()
+
+	call.err = err
+	close(call.done)
+}
+
+// Login logs in to the private API unless the current session is still valid
+func (f *Fusebill) Login() error {
+	return f.LoginContext(context.Background())
+}
+
+// LoginContext logs in to the private API unless the current session is still valid, using the ctx for cancellation
+func (f *Fusebill) LoginContext(ctx context.Context) error {
+	_, err := f.ensureSession(ctx)
+	return err
+}
+
+// SessionValid reports whether the private API session can be reused without logging in
+func (f *Fusebill) SessionValid() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sessionValid(time.Now())
+}
+
+func (f *Fusebill) sessionTTL() time.Duration {
+	if f.SessionTTL <= 0 {
+		return DefaultSessionTTL
+	}
+
+	return f.SessionTTL
+}
+
+func (f *Fusebill) sessionValid(now time.Time) bool {
+	return !f.sessionExpires.IsZero() && now.Add(sessionRefreshMargin).Before(f.sessionExpires)
+}
+
+// ensureSession logs in when the session is missing or near expiry and returns
+// the generation of the session to use
+func (f *Fusebill) ensureSession(ctx context.Context) (uint64, error) {
+	if f.cookieJar == nil {
+		return 0, errors.New("cookie jar should be set")
+	}
+
+	f.mu.Lock()
+	valid, generation := f.sessionValid(time.Now()), f.authGeneration
+	f.mu.Unlock()
+	if valid {
+		return generation, nil
+	}
+
+	if err := f.reauthenticate(ctx, generation); err != nil {
+		return 0, err
+	}
+
+	return f.generation(), nil
+}
@@ -0,0 +1,329 @@
+package fusebill
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetTokenWhileRequestsInFlight(t *testing.T) {
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Basic token-") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	f.SetToken("token-0")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := f.SendRequest(RequestDetails{Method: "GET", Endpoint: "/customers/1"}); err != nil {
+				errs <- err
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			f.SetToken("token-" + strconv.Itoa(i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSetTokenResendsRejectedRequest(t *testing.T) {
+	var authorizations []string
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Basic rotated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// the token is rotated after the request was built with the old one
+	f.RequestModifier = func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Basic rotated" {
+			f.SetToken("rotated")
+		}
+		return nil
+	}
+
+	if _, err := f.SendRequest(RequestDetails{Method: "GET", Endpoint: "/customers/1"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Basic " + TestToken, "Basic rotated"}
+	if strings.Join(authorizations, ",") != strings.Join(want, ",") {
+		t.Errorf("sent %v, want %v", authorizations, want)
+	}
+
+	authorizations = nil
+	f.RequestModifier = nil
+	f.SetToken("revoked")
+	if _, err := f.SendRequest(RequestDetails{Method: "GET", Endpoint: "/customers/1"}); err == nil {
+		t.Error("request with a rejected token succeeded")
+	}
+	if len(authorizations) != 1 {
+		t.Errorf("sent %d requests with an unchanged rejected token, want 1", len(authorizations))
+	}
+}
+
+func TestConcurrentCallersShareLogin(t *testing.T) {
+	var logins int32
+	f, server := NewTestPrivateClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == DefaultLoginPath {
+			atomic.AddInt32(&logins, 1)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f.WriteOff("1", 5); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Errorf("logged in %d times, want 1", n)
+	}
+}
+
+func TestConcurrentCallersShareFailedLogin(t *testing.T) {
+	var logins int32
+	f, server := NewTestPrivateClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == DefaultLoginPath {
+			atomic.AddInt32(&logins, 1)
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	var failed int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if f.WriteOff("1", 5) != nil {
+				atomic.AddInt32(&failed, 1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if failed != 20 {
+		t.Errorf("%d of 20 write-offs failed, want all", failed)
+	}
+	if n := atomic.LoadInt32(&logins); n >= 20 {
+		t.Errorf("logged in %d times, want the callers to share the attempt", n)
+	}
+}
+
+func TestCancelledCallerLeavesSharedLogin(t *testing.T) {
+	var logins int32
+	loggingIn, release := make(chan struct{}), make(chan struct{})
+	f, server := NewTestPrivateClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == DefaultLoginPath {
+			if atomic.AddInt32(&logins, 1) == 1 {
+				close(loggingIn)
+			}
+			<-release
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() { first <- f.WriteOffContext(ctx, "1", 5) }()
+	<-loggingIn
+
+	second := make(chan error, 1)
+	go func() { second <- f.WriteOff("1", 5) }()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller got %v, want context.Canceled", err)
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("waiting caller got %v, want the shared login to succeed", err)
+	}
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Errorf("logged in %d times, want 1", n)
+	}
+}
+
+func TestPrivateCallWithoutCookieJar(t *testing.T) {
+	var requests int32
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if err := f.WriteOff("1", 5); err == nil {
+		t.Error("WriteOff succeeded without a cookie jar")
+	}
+	if err := f.Login(); err == nil {
+		t.Error("Login succeeded without a cookie jar")
+	}
+	if err := f.reauthenticate(context.Background(), f.generation()); err == nil {
+		t.Error("reauthenticate succeeded without a cookie jar or RefreshToken")
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("sent %d requests, want none", n)
+	}
+
+	if _, err := f.SendRequest(RequestDetails{Method: "GET", Endpoint: "/customers/1"}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("got %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestRefreshTokenOn401(t *testing.T) {
+	var refreshes int32
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Basic fresh" {
+			time.Sleep(10 * time.Millisecond)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	f.RefreshToken = func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&refreshes, 1)
+		time.Sleep(50 * time.Millisecond)
+		return "fresh", nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.SendRequest(RequestDetails{Method: "GET", Endpoint: "/customers/1"}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := atomic.LoadInt32(&refreshes); n != 1 {
+		t.Errorf("token refreshed %d times, want 1", n)
+	}
+}
+
+func TestReloginOnExpiredSession(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(f *Fusebill)
+		reject func(w http.ResponseWriter, r *http.Request)
+	}{
+		{"unauthorized", nil, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}},
+		{"redirect not followed", func(f *Fusebill) {
+			f.Client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+		}, func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}},
+		{"redirect to login page", nil, func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}},
+		{"login page", nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>login</html>"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logins, writeOffs int
+			f, server := NewTestPrivateClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case DefaultLoginPath:
+					logins++
+				case "/login":
+					w.Header().Set("Content-Type", "text/html")
+					w.Write([]byte("<html>login</html>"))
+				case writeOffEndpoint:
+					writeOffs++
+					if logins < 2 {
+						tt.reject(w, r)
+					}
+				}
+			}))
+			defer server.Close()
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			if err := f.WriteOff("1", 5); err != nil {
+				t.Fatal(err)
+			}
+			if logins != 2 || writeOffs != 2 {
+				t.Errorf("got %d logins and %d write-offs, want 2 of each", logins, writeOffs)
+			}
+		})
+	}
+}
+
+func TestExpiredSessionAfterRelogin(t *testing.T) {
+	var logins int
+	f, server := NewTestPrivateClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == DefaultLoginPath {
+			logins++
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>login</html>"))
+	}))
+	defer server.Close()
+
+	if err := f.WriteOff("1", 5); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("got %v, want ErrSessionExpired", err)
+	}
+	if logins != 2 {
+		t.Errorf("got %d logins, want 2", logins)
+	}
+}
@@ -0,0 +1,57 @@
+package fusebill
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// WriteOffBatchResult is the outcome of writing off one item of a batch
+type WriteOffBatchResult struct {
+	InvoiceId int
+	Err       error
+}
+
+// WriteOffBatch writes the invoices off with at most concurrency requests in flight
+func (f *Fusebill) WriteOffBatch(items []WriteOff, concurrency int) ([]WriteOffBatchResult, error) {
+	return f.WriteOffBatchContext(context.Background(), items, concurrency)
+}
+
+// WriteOffBatchContext writes the invoices off using the ctx for cancellation.
+// It logs in once up front and returns an error only when that fails, the
+// outcome of every item is reported in the result at the same index.
+func (f *Fusebill) WriteOffBatchContext(ctx context.Context, items []WriteOff, concurrency int) ([]WriteOffBatchResult, error) {
+	if !f.DryRun {
+		if err := f.LoginContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]WriteOffBatchResult, len(items))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item := items[i]
+				_, err := f.writeOff(ctx, strconv.Itoa(item.InvoiceId), item.Amount, item.Note, "")
+				results[i] = WriteOffBatchResult{InvoiceId: item.InvoiceId, Err: err}
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, nil
+}
@@ -0,0 +1,64 @@
+package fusebill
+
+import "context"
+
+type Price struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+type Product struct {
+	Id          int     `json:"id"`
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Status      string  `json:"status"`
+	ProductType string  `json:"productType"`
+	Prices      []Price `json:"prices"`
+}
+
+type Plan struct {
+	Id        int     `json:"id"`
+	ProductId int     `json:"productId"`
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Interval  string  `json:"interval"`
+	Prices    []Price `json:"prices"`
+}
+
+// ListProducts returns a page of the product catalog
+func (f *Fusebill) ListProducts(opts ListOptions) ([]Product, error) {
+	return f.ListProductsContext(context.Background(), opts)
+}
+
+// ListProductsContext returns a page of the product catalog using the ctx for cancellation
+func (f *Fusebill) ListProductsContext(ctx context.Context, opts ListOptions) ([]Product, error) {
+	products := []Product{}
+	if err := f.list(ctx, "/products", nil, opts, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// ListPlans returns all the plans of the product
+func (f *Fusebill) ListPlans(productID string) ([]Plan, error) {
+	return f.ListPlansContext(context.Background(), productID)
+}
+
+// ListPlansContext returns all the plans of the product using the ctx for cancellation, fetching every page
+func (f *Fusebill) ListPlansContext(ctx context.Context, productID string) ([]Plan, error) {
+	plans := []Plan{}
+	filter := NewQuery().Equals("productId", productID)
+	for opts := (ListOptions{}); ; opts = opts.Next() {
+		page := []Plan{}
+		if err := f.list(ctx, "/plans", filter, opts, &page); err != nil {
+			return nil, err
+		}
+
+		plans = append(plans, page...)
+		if !opts.hasMore(len(page)) {
+			return plans, nil
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package fusebill
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipBody decompresses a gzip encoded response body, the gzip reader is created
+// on the first Read so empty bodies, e.g. of a 204, are not an error
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.zr == nil && g.err == nil {
+		g.zr, g.err = gzip.NewReader(g.body)
+		if g.err == io.EOF {
+			g.err = nil
+			return 0, io.EOF
+		}
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+
+	return g.zr.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	return g.body.Close()
+}
+
+// decompress replaces a gzip encoded body of the response with its decompressed content
+func decompress(resp *http.Response) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
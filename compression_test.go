@@ -0,0 +1,92 @@
+package fusebill
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestGzipBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    []byte
+		want    string
+		wantErr bool
+	}{
+		{"compressed", gzipped(t, `{"id":1}`), `{"id":1}`, false},
+		{"empty", nil, "", false},
+		{"compressed empty", gzipped(t, ""), "", false},
+		{"corrupt", []byte("not gzip"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(&gzipBody{body: io.NopCloser(bytes.NewReader(tt.body))})
+			if string(got) != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("got %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		encoding string
+		body     []byte
+		want     string
+	}{
+		{"gzip", gzipped(t, "hello"), "hello"},
+		{"GZIP", gzipped(t, "hello"), "hello"},
+		{"", []byte("hello"), "hello"},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(tt.body)), ContentLength: int64(len(tt.body))}
+		if tt.encoding != "" {
+			resp.Header.Set("Content-Encoding", tt.encoding)
+		}
+
+		decompress(resp)
+		got, err := io.ReadAll(resp.Body)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%q: got %q, %v, want %q", tt.encoding, got, err, tt.want)
+		}
+		if tt.encoding != "" && (resp.Header.Get("Content-Encoding") != "" || resp.ContentLength != -1 || !resp.Uncompressed) {
+			t.Errorf("%q: the encoding headers are kept", tt.encoding)
+		}
+	}
+}
+
+func TestCompressedResponse(t *testing.T) {
+	body := gzipped(t, `{"id":7}`)
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	customer, err := f.GetCustomer("7")
+	if err != nil || customer.Id != 7 {
+		t.Errorf("got %+v, %v", customer, err)
+	}
+}
@@ -0,0 +1,60 @@
+package fusebill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Config is the content of the JSON file read by NewClientFromConfig
+type Config struct {
+	Mode     string `json:"mode"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// credentials returns the credentials of the config, the token taking precedence
+func (c Config) credentials() (Credentials, error) {
+	if c.Token != "" {
+		return Credentials{Token: c.Token}, nil
+	}
+	if c.Username == "" || c.Password == "" {
+		return Credentials{}, errors.New("token or username and password should be set")
+	}
+
+	return Credentials{Username: c.Username, Password: c.Password}, nil
+}
+
+// NewClientFromConfig returns the new fusebill client configured by the JSON file at path,
+// e.g. {"mode": "production", "token": "..."}. Unknown keys are rejected to catch typos.
+func NewClientFromConfig(path string, opts ...Option) (*Fusebill, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config := Config{}
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("config %s is malformed: %w", path, err)
+	}
+
+	if config.Mode == "" {
+		return nil, fmt.Errorf("config %s: mode should be set", path)
+	}
+	credentials, err := config.credentials()
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	client := NewClient(config.Mode, credentials, opts...)
+	if err := client.Validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	return client, nil
+}
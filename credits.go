@@ -0,0 +1,149 @@
+package fusebill
+
+import (
+	"context"
+	"fmt"
+)
+
+type Credit struct {
+	Id              int     `json:"id"`
+	CustomerId      int     `json:"customerId"`
+	Amount          float64 `json:"amount"`
+	Reason          string  `json:"reference"`
+	Status          string  `json:"status"`
+	RemainingAmount float64 `json:"unallocatedAmount"`
+}
+
+type creditRequest struct {
+	CustomerId int     `json:"customerId"`
+	Amount     float64 `json:"amount"`
+	Reference  string  `json:"reference"`
+}
+
+type creditApplication struct {
+	CreditId  int     `json:"creditId"`
+	InvoiceId int     `json:"invoiceId"`
+	Amount    float64 `json:"amount"`
+}
+
+// CreateCredit issues a credit to the customer
+func (f *Fusebill) CreateCredit(customerID string, amount float64, reason string) (*Credit, error) {
+	return f.CreateCreditWithKeyContext(context.Background(), customerID, amount, reason, "")
+}
+
+// CreateCreditWithKeyContext issues a credit to the customer sending the idempotency key, using the ctx for cancellation.
+// A random key is generated when key is empty.
+func (f *Fusebill) CreateCreditWithKeyContext(ctx context.Context, customerID string, amount float64, reason, key string) (*Credit, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("Customer %s: credit amount is %.2f", customerID, amount)
+	}
+
+	if key == "" {
+		var err error
+		if key, err = newIdempotencyKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	i, err := parseID("Customer", customerID)
+	if err != nil {
+		return nil, err
+	}
+	data := &creditRequest{CustomerId: i, Amount: amount, Reference: reason}
+
+	endpoint := "/credits"
+	credit := &Credit{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "POST", Endpoint: endpoint, Payload: data, IdempotencyKey: key}, credit); err != nil {
+		return nil, err
+	}
+
+	return credit, nil
+}
+
+// ApplyCredit applies the amount of the credit to the invoice
+func (f *Fusebill) ApplyCredit(creditID, invoiceID string, amount float64) error {
+	return f.ApplyCreditWithKeyContext(context.Background(), creditID, invoiceID, amount, "")
+}
+
+// ApplyCreditWithKeyContext applies the amount of the credit to the invoice sending the idempotency key,
+// using the ctx for cancellation. A random key is generated when key is empty.
+func (f *Fusebill) ApplyCreditWithKeyContext(ctx context.Context, creditID, invoiceID string, amount float64, key string) error {
+	if amount <= 0 {
+		return fmt.Errorf("Credit %s: applied amount is %.2f", creditID, amount)
+	}
+
+	if key == "" {
+		var err error
+		if key, err = newIdempotencyKey(); err != nil {
+			return err
+		}
+	}
+
+	c, err := parseID("Credit", creditID)
+	if err != nil {
+		return err
+	}
+	i, err := parseID("Invoice", invoiceID)
+	if err != nil {
+		return err
+	}
+	data := &creditApplication{CreditId: c, InvoiceId: i, Amount: amount}
+
+	_, err = f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/creditAllocations", Payload: data, IdempotencyKey: key})
+	return err
+}
+
+// ListCredits returns all the credit memos of the customer
+func (f *Fusebill) ListCredits(customerID string) ([]Credit, error) {
+	return f.ListCreditsContext(context.Background(), customerID)
+}
+
+// ListCreditsContext returns all the credit memos of the customer using the ctx for cancellation,
+// fetching every page. IterateCredits walks them page by page instead.
+func (f *Fusebill) ListCreditsContext(ctx context.Context, customerID string) ([]Credit, error) {
+	credits := []Credit{}
+	it := f.IterateCredits(ctx, customerID, ListOptions{})
+	for it.Next() {
+		credits = append(credits, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+// CreditIterator walks the credit memos of a customer fetching pages as needed
+type CreditIterator struct {
+	iterator
+	page []Credit
+}
+
+// IterateCredits returns an iterator over the customer's credit memos starting at the page opts selects
+func (f *Fusebill) IterateCredits(ctx context.Context, customerID string, opts ListOptions) *CreditIterator {
+	it := &CreditIterator{}
+	it.ctx, it.opts = ctx, opts
+	it.fetch = func(ctx context.Context, page ListOptions) (int, error) {
+		credits := []Credit{}
+		err := f.list(ctx, "/customers/"+customerID+"/credits", nil, page, &credits)
+		it.page = credits
+		return len(credits), err
+	}
+
+	return it
+}
+
+// Next advances to the next credit memo, it returns false at the end or on error
+func (it *CreditIterator) Next() bool {
+	return it.next()
+}
+
+// Value returns the current credit memo
+func (it *CreditIterator) Value() Credit {
+	return it.page[it.pos]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *CreditIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,351 @@
+package fusebill
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type Customer struct {
+	Id                     int     `json:"id"`
+	FirstName              string  `json:"firstName"`
+	LastName               string  `json:"lastName"`
+	PrimaryEmail           string  `json:"primaryEmail"`
+	Status                 string  `json:"status"`
+	CustomerAccountBalance float64 `json:"customerAccountBalance"`
+}
+
+type Address struct {
+	Line1       string `json:"line1,omitempty"`
+	Line2       string `json:"line2,omitempty"`
+	City        string `json:"city,omitempty"`
+	State       string `json:"state,omitempty"`
+	Country     string `json:"country,omitempty"`
+	PostalZip   string `json:"postalZip,omitempty"`
+	CompanyName string `json:"companyName,omitempty"`
+}
+
+type CreateCustomerRequest struct {
+	FirstName    string   `json:"firstName"`
+	LastName     string   `json:"lastName"`
+	PrimaryEmail string   `json:"primaryEmail"`
+	Address      *Address `json:"address,omitempty"`
+}
+
+// CustomerUpdate holds the customer fields to change, nil fields are left untouched
+type CustomerUpdate struct {
+	FirstName    *string  `json:"firstName,omitempty"`
+	LastName     *string  `json:"lastName,omitempty"`
+	PrimaryEmail *string  `json:"primaryEmail,omitempty"`
+	Address      *Address `json:"address,omitempty"`
+}
+
+// GetCustomer returns the customer
+func (f *Fusebill) GetCustomer(customerID string) (*Customer, error) {
+	return f.GetCustomerContext(context.Background(), customerID)
+}
+
+// GetCustomerContext returns the customer using the ctx for cancellation
+func (f *Fusebill) GetCustomerContext(ctx context.Context, customerID string) (*Customer, error) {
+	endpoint := "/customers/" + customerID
+	customer := &Customer{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "GET", Endpoint: endpoint}, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// CreateCustomer creates the customer and returns it with its new Id
+func (f *Fusebill) CreateCustomer(req CreateCustomerRequest) (*Customer, error) {
+	return f.CreateCustomerContext(context.Background(), req)
+}
+
+// CreateCustomerContext creates the customer using the ctx for cancellation.
+// Validation failures are returned as *APIError.
+func (f *Fusebill) CreateCustomerContext(ctx context.Context, req CreateCustomerRequest) (*Customer, error) {
+	endpoint := "/customers"
+	customer := &Customer{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "POST", Endpoint: endpoint, Payload: req}, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// GetCustomerBalance returns the account balance of the customer
+func (f *Fusebill) GetCustomerBalance(customerID string) (float64, error) {
+	return f.GetCustomerBalanceContext(context.Background(), customerID)
+}
+
+// GetCustomerBalanceContext returns the account balance of the customer using the ctx for cancellation.
+// A response without customerAccountBalance is reported as a decode error rather than read as zero.
+func (f *Fusebill) GetCustomerBalanceContext(ctx context.Context, customerID string) (float64, error) {
+	endpoint := "/customers/" + customerID
+	resp, err := f.SendRequestContext(ctx, RequestDetails{Method: "GET", Endpoint: endpoint})
+	if err != nil {
+		return 0, err
+	}
+
+	customer := &struct {
+		Customer
+		CustomerAccountBalance *float64 `json:"customerAccountBalance"`
+	}{}
+	if err := requireContent(endpoint, resp); err != nil {
+		return 0, err
+	}
+	if err := f.decodeResponse(endpoint, resp, customer); err != nil {
+		return 0, err
+	}
+	if customer.CustomerAccountBalance == nil {
+		return 0, decodeError(endpoint, resp.Body, errors.New("customerAccountBalance is missing"))
+	}
+
+	return *customer.CustomerAccountBalance, nil
+}
+
+// FindCustomersByEmail returns the customers whose primary email is email, an empty slice when there are none
+func (f *Fusebill) FindCustomersByEmail(email string) ([]Customer, error) {
+	return f.FindCustomersByEmailContext(context.Background(), email)
+}
+
+// FindCustomersByEmailContext returns the customers whose primary email is email, using the ctx for cancellation
+func (f *Fusebill) FindCustomersByEmailContext(ctx context.Context, email string) ([]Customer, error) {
+	customers := []Customer{}
+	filter := NewQuery().Equals("primaryEmail", email)
+	for opts := (ListOptions{}); ; opts = opts.Next() {
+		page := []Customer{}
+		if err := f.list(ctx, "/customers", filter, opts, &page); err != nil {
+			return nil, err
+		}
+
+		customers = append(customers, page...)
+		if !opts.hasMore(len(page)) {
+			return customers, nil
+		}
+	}
+}
+
+// UpdateCustomer changes the fields set in patch and returns the updated customer
+func (f *Fusebill) UpdateCustomer(customerID string, patch CustomerUpdate) (*Customer, error) {
+	return f.UpdateCustomerContext(context.Background(), customerID, patch)
+}
+
+// UpdateCustomerContext changes the fields set in patch using the ctx for cancellation
+func (f *Fusebill) UpdateCustomerContext(ctx context.Context, customerID string, patch CustomerUpdate) (*Customer, error) {
+	endpoint := "/customers/" + customerID
+	customer := &Customer{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "PATCH", Endpoint: endpoint, Payload: patch}, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// CustomerListOptions selects and filters the customers returned by ListCustomers
+type CustomerListOptions struct {
+	ListOptions
+	// Status, when set, keeps the customers in that status, e.g. "Active"
+	Status string
+	// CreatedAfter and CreatedBefore, when set, bound the creation date of the customers
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+func (o CustomerListOptions) filter() *QueryBuilder {
+	q := NewQuery()
+	if o.Status != "" {
+		q.Equals("status", o.Status)
+	}
+	if !o.CreatedAfter.IsZero() || !o.CreatedBefore.IsZero() {
+		q.Between("createdTimestamp", o.CreatedAfter, o.CreatedBefore)
+	}
+
+	return q
+}
+
+// ListCustomers returns a page of the customers matching opts
+func (f *Fusebill) ListCustomers(opts CustomerListOptions) ([]Customer, error) {
+	return f.ListCustomersContext(context.Background(), opts)
+}
+
+// ListCustomersContext returns a page of the customers matching opts using the ctx for cancellation
+func (f *Fusebill) ListCustomersContext(ctx context.Context, opts CustomerListOptions) ([]Customer, error) {
+	page, err := f.ListCustomersPageContext(ctx, opts)
+	return page.Customers, err
+}
+
+// CustomerPage is a page of customers returned by ListCustomersPage and ListCustomersPageContext
+type CustomerPage struct {
+	Customers []Customer
+	// HasMore is false once a page comes back with fewer customers than requested
+	HasMore bool
+	// Next selects the following page
+	Next CustomerListOptions
+	// PageInfo reports the total number of customers when Fusebill provides it
+	PageInfo PageInfo
+}
+
+// ListCustomersPage returns a page of the customers matching opts along with whether more pages remain
+func (f *Fusebill) ListCustomersPage(opts CustomerListOptions) (CustomerPage, error) {
+	return f.ListCustomersPageContext(context.Background(), opts)
+}
+
+// ListCustomersPageContext returns a page of the customers matching opts along with whether more pages
+// remain, using the ctx for cancellation
+func (f *Fusebill) ListCustomersPageContext(ctx context.Context, opts CustomerListOptions) (CustomerPage, error) {
+	customers := []Customer{}
+	info, err := f.listPage(ctx, "/customers", opts.filter(), opts.ListOptions, &customers)
+	if err != nil {
+		return CustomerPage{}, err
+	}
+
+	next := opts
+	next.ListOptions = opts.Next()
+	return CustomerPage{Customers: customers, HasMore: opts.hasMore(len(customers)), Next: next, PageInfo: info}, nil
+}
+
+// CustomerIterator walks the customers matching the options fetching pages as needed
+type CustomerIterator struct {
+	iterator
+	page []Customer
+}
+
+// IterateCustomers returns an iterator over the customers matching opts starting at the page it selects
+func (f *Fusebill) IterateCustomers(opts CustomerListOptions) *CustomerIterator {
+	return f.IterateCustomersContext(context.Background(), opts)
+}
+
+// IterateCustomersContext returns an iterator over the customers matching opts starting at the page
+// it selects, fetching the pages using the ctx for cancellation
+func (f *Fusebill) IterateCustomersContext(ctx context.Context, opts CustomerListOptions) *CustomerIterator {
+	it := &CustomerIterator{}
+	it.ctx, it.opts = ctx, opts.ListOptions
+	it.fetch = func(ctx context.Context, page ListOptions) (int, error) {
+		opts.ListOptions = page
+		customers, err := f.ListCustomersContext(ctx, opts)
+		it.page = customers
+		return len(customers), err
+	}
+
+	return it
+}
+
+// Next advances to the next customer, it returns false at the end or on error
+func (it *CustomerIterator) Next() bool {
+	return it.next()
+}
+
+// Value returns the current customer
+func (it *CustomerIterator) Value() Customer {
+	return it.page[it.pos]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *CustomerIterator) Err() error {
+	return it.err
+}
+
+// FinancialSummary totals the invoices and payments of a customer, failed payments are not counted
+type FinancialSummary struct {
+	CustomerId       int
+	TotalOutstanding float64
+	TotalPaid        float64
+	OpenInvoices     int
+}
+
+// GetCustomerFinancialSummary returns the totals of the customer's invoices and payments
+func (f *Fusebill) GetCustomerFinancialSummary(customerID string) (*FinancialSummary, error) {
+	return f.GetCustomerFinancialSummaryContext(context.Background(), customerID)
+}
+
+// GetCustomerFinancialSummaryContext returns the totals of the customer's invoices and payments
+// using the ctx for cancellation. Fusebill has no summary endpoint, so every page of the
+// customer's invoices and payments is fetched and added up. The pages are not read from a
+// single snapshot: an invoice or payment changed meanwhile may be counted in its old or new state.
+func (f *Fusebill) GetCustomerFinancialSummaryContext(ctx context.Context, customerID string) (*FinancialSummary, error) {
+	i, err := parseID("Customer", customerID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &FinancialSummary{CustomerId: i}
+
+	for opts := (ListOptions{}); ; opts = opts.Next() {
+		page, err := f.ListInvoicesPageContext(ctx, customerID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, invoice := range page.Invoices {
+			if invoice.OutstandingBalance > 0 {
+				summary.TotalOutstanding += invoice.OutstandingBalance
+				summary.OpenInvoices++
+			}
+		}
+		if !page.HasMore {
+			break
+		}
+	}
+
+	for opts := (ListOptions{}); ; opts = opts.Next() {
+		payments, err := f.ListPaymentsContext(ctx, customerID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, payment := range payments {
+			if payment.Status != "Failed" {
+				summary.TotalPaid += payment.Amount
+			}
+		}
+		if !opts.hasMore(len(payments)) {
+			break
+		}
+	}
+
+	return summary, nil
+}
+
+// SuspendCustomer suspends the customer, e.g. for non-payment
+func (f *Fusebill) SuspendCustomer(customerID string) error {
+	return f.SuspendCustomerContext(context.Background(), customerID)
+}
+
+// SuspendCustomerContext suspends the customer using the ctx for cancellation
+func (f *Fusebill) SuspendCustomerContext(ctx context.Context, customerID string) error {
+	_, err := f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/customerSuspension/" + customerID})
+	return err
+}
+
+// ReactivateCustomer reactivates the suspended customer
+func (f *Fusebill) ReactivateCustomer(customerID string) error {
+	return f.ReactivateCustomerContext(context.Background(), customerID)
+}
+
+// ReactivateCustomerContext reactivates the suspended customer using the ctx for cancellation
+func (f *Fusebill) ReactivateCustomerContext(ctx context.Context, customerID string) error {
+	_, err := f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/customerReactivation/" + customerID})
+	return err
+}
+
+// ActivityEntry is an event of a customer's activity log, e.g. a status change or a payment
+type ActivityEntry struct {
+	Timestamp   Date   `json:"timestamp"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// GetCustomerActivity returns a page of the customer's activity log
+func (f *Fusebill) GetCustomerActivity(customerID string, opts ListOptions) ([]ActivityEntry, error) {
+	return f.GetCustomerActivityContext(context.Background(), customerID, opts)
+}
+
+// GetCustomerActivityContext returns a page of the customer's activity log using the ctx for cancellation
+func (f *Fusebill) GetCustomerActivityContext(ctx context.Context, customerID string, opts ListOptions) ([]ActivityEntry, error) {
+	entries := []ActivityEntry{}
+	if err := f.list(ctx, "/customers/"+customerID+"/activities", nil, opts, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
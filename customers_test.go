@@ -0,0 +1,53 @@
+package fusebill
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListCustomersPage(t *testing.T) {
+	var query string
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		w.Write([]byte(`[{"id":1},{"id":2}]`))
+	}))
+	defer server.Close()
+
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	page, err := f.ListCustomersPage(CustomerListOptions{ListOptions: ListOptions{Limit: 2}, Status: "Active", CreatedAfter: after})
+	if err != nil || len(page.Customers) != 2 || !page.HasMore || page.Next.Offset != 2 || page.Next.Status != "Active" {
+		t.Fatalf("got %+v, %v", page, err)
+	}
+	if want := "status:Active;createdTimestamp:2026-01-01T00:00:00|"; query != want {
+		t.Errorf("sent query %q, want %q", query, want)
+	}
+}
+
+func TestIterateCustomers(t *testing.T) {
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Query().Get("query"), "status:Active") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		p, _ := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+		if p == 1 {
+			fmt.Fprint(w, `[{"id":3}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+	}))
+	defer server.Close()
+
+	it := f.IterateCustomers(CustomerListOptions{ListOptions: ListOptions{Limit: 2}, Status: "Active"})
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Value().Id)
+	}
+	if it.Err() != nil || fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("got %v, %v, want the three customers", ids, it.Err())
+	}
+}
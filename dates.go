@@ -0,0 +1,123 @@
+package fusebill
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DateLayout is the layout of the dates Fusebill returns without a time zone
+const DateLayout = "2006-01-02T15:04:05"
+
+// Date is a date returned by Fusebill, which omits the offset of most dates. UnmarshalJSON
+// reads such a date in UTC, the client then moves it to Fusebill.Location keeping its wall
+// clock. A date carrying an offset keeps its instant. An empty or null date is the zero time.
+type Date struct {
+	time.Time
+	// offsetless is set for a date received without an offset
+	offsetless bool
+}
+
+// UnmarshalJSON parses the date in any of the layouts Fusebill uses
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var value *string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if value == nil {
+		*d = Date{}
+		return nil
+	}
+
+	t, offsetless, err := parseDate(*value)
+	if err != nil {
+		return err
+	}
+
+	*d = Date{Time: t, offsetless: offsetless}
+	return nil
+}
+
+// in returns the date in loc, reading the wall clock of an offsetless date in it
+func (d Date) in(loc *time.Location) Date {
+	if d.IsZero() {
+		return d
+	}
+	if !d.offsetless {
+		return Date{Time: d.Time.In(loc)}
+	}
+
+	t := time.Date(d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), loc)
+	return Date{Time: t, offsetless: true}
+}
+
+// parseDate parses a Fusebill date in UTC and reports whether it lacked an offset.
+// A date carrying an offset is moved to UTC, an empty one is the zero time.
+func parseDate(value string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), false, nil
+	}
+	for _, layout := range []string{DateLayout, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, true, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("date %q is not in a known format", value)
+}
+
+func (f *Fusebill) location() *time.Location {
+	if f.Location == nil {
+		return time.UTC
+	}
+
+	return f.Location
+}
+
+var dateType = reflect.TypeOf(Date{})
+
+// localizeDates moves every Date reachable from out to loc, e.g. the dates of a decoded
+// invoice, of the invoices in a slice or of an invoice nested in a caller's struct
+func localizeDates(out interface{}, loc *time.Location) {
+	if loc != time.UTC {
+		localize(reflect.ValueOf(out), loc)
+	}
+}
+
+func localize(v reflect.Value, loc *time.Location) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			localize(v.Elem(), loc)
+		}
+	case reflect.Struct:
+		if v.Type() == dateType {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(v.Interface().(Date).in(loc)))
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				localize(v.Field(i), loc)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			localize(v.Index(i), loc)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			localize(value, loc)
+			v.SetMapIndex(iter.Key(), value)
+		}
+	}
+}
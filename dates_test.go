@@ -0,0 +1,115 @@
+package fusebill
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value      string
+		want       time.Time
+		offsetless bool
+		wantErr    bool
+	}{
+		{"", time.Time{}, false, false},
+		{"2026-01-31T23:30:00", time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC), true, false},
+		{"2026-02-01", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), true, false},
+		{"2026-01-31T23:30:00Z", time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC), false, false},
+		{"2026-01-31T23:30:00.5-05:00", time.Date(2026, 2, 1, 4, 30, 0, 5e8, time.UTC), false, false},
+		{"31/01/2026", time.Time{}, false, true},
+	}
+
+	for _, tt := range tests {
+		got, offsetless, err := parseDate(tt.value)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) || got.Location() != time.UTC || offsetless != tt.offsetless {
+			t.Errorf("parseDate(%q) = %v, %v, %v, want %v, %v", tt.value, got, offsetless, err, tt.want, tt.offsetless)
+		}
+	}
+}
+
+func TestDateUnmarshalJSON(t *testing.T) {
+	var entry ActivityEntry
+	if err := json.Unmarshal([]byte(`{"timestamp":"2026-01-31T23:30:00","type":"Payment"}`), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC); !entry.Timestamp.Equal(want) {
+		t.Errorf("got %v, want %v", entry.Timestamp, want)
+	}
+
+	for _, value := range []string{`null`, `""`} {
+		var d Date
+		if err := json.Unmarshal([]byte(value), &d); err != nil || !d.IsZero() {
+			t.Errorf("Unmarshal(%s) = %v, %v, want the zero date", value, d, err)
+		}
+	}
+
+	var d Date
+	if err := json.Unmarshal([]byte(`"tomorrow"`), &d); err == nil {
+		t.Error("an unknown layout was accepted")
+	}
+}
+
+func TestDecodeDatesInLocation(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invoices/1":
+			w.Write([]byte(`{"id":1,"invoiceDate":"2026-01-31T23:30:00","dueDate":"2026-02-01T10:00:00Z","outstandingBalance":5}`))
+		case "/activity":
+			w.Write([]byte(`{"timestamp":"2026-01-31T23:30:00"}`))
+		case "/statement":
+			w.Write([]byte(`{"invoices":[{"id":1,"invoiceDate":"2026-01-31T23:30:00"}],"extra":{"due":{"id":2,"dueDate":"2026-01-31"}}}`))
+		}
+	}))
+	defer server.Close()
+	f.Location = loc
+	f.DisallowUnknownFields = true
+
+	invoice, err := f.GetInvoice("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 31, 23, 30, 0, 0, loc); !invoice.InvoiceDate.Equal(want) || invoice.InvoiceDate.Location() != loc {
+		t.Errorf("got invoice date %v, want %v", invoice.InvoiceDate, want)
+	}
+	if want := time.Date(2026, 2, 1, 5, 0, 0, 0, loc); !invoice.DueDate.Equal(want) || invoice.DueDate.Location() != loc {
+		t.Errorf("got due date %v, want %v", invoice.DueDate, want)
+	}
+
+	var entry ActivityEntry
+	if err := f.Decode(RequestDetails{Method: "GET", Endpoint: "/activity"}, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 31, 23, 30, 0, 0, loc); !entry.Timestamp.Equal(want) {
+		t.Errorf("got %v, want %v", entry.Timestamp, want)
+	}
+
+	var statement struct {
+		Invoices []Invoice          `json:"invoices"`
+		Extra    map[string]Invoice `json:"extra"`
+	}
+	if err := f.Decode(RequestDetails{Method: "GET", Endpoint: "/statement"}, &statement); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 31, 23, 30, 0, 0, loc); len(statement.Invoices) != 1 || !statement.Invoices[0].InvoiceDate.Equal(want) {
+		t.Errorf("got %+v, want an invoice dated %v", statement.Invoices, want)
+	}
+	if want := time.Date(2026, 1, 31, 0, 0, 0, 0, loc); !statement.Extra["due"].DueDate.Equal(want) {
+		t.Errorf("got %v, want %v", statement.Extra["due"].DueDate, want)
+	}
+}
+
+func TestStrictDecodingWithDates(t *testing.T) {
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"invoiceDate":"2026-01-31","outstandingBalance":5,"unknown":true}`))
+	}))
+	defer server.Close()
+	f.DisallowUnknownFields = true
+
+	if _, err := f.GetInvoice("1"); err == nil {
+		t.Error("an unknown field was accepted")
+	}
+}
@@ -0,0 +1,39 @@
+package fusebill
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// DryRunRequest is a mutating request recorded instead of being sent in dry-run mode
+type DryRunRequest struct {
+	Method   string
+	Endpoint string
+	Body     []byte
+}
+
+// DryRunRequests returns the requests recorded so far in dry-run mode
+func (f *Fusebill) DryRunRequests() []DryRunRequest {
+	f.dryRunMu.Lock()
+	defer f.dryRunMu.Unlock()
+	return append([]DryRunRequest(nil), f.dryRunRequests...)
+}
+
+// isMutation reports whether a request with the method may change data
+func isMutation(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead
+}
+
+// recordDryRun records the request and returns the empty successful response it is answered with
+func (f *Fusebill) recordDryRun(r RequestDetails, body []byte) *http.Response {
+	f.dryRunMu.Lock()
+	f.dryRunRequests = append(f.dryRunRequests, DryRunRequest{Method: r.Method, Endpoint: r.Endpoint, Body: body})
+	f.dryRunMu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte("{}"))),
+	}
+}
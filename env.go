@@ -0,0 +1,42 @@
+package fusebill
+
+import (
+	"errors"
+	"os"
+)
+
+// Environment variables read by NewClientFromEnv
+const (
+	EnvToken    = "FUSEBILL_TOKEN"
+	EnvUsername = "FUSEBILL_USERNAME"
+	EnvPassword = "FUSEBILL_PASSWORD"
+)
+
+// NewClientFromEnv returns the new fusebill client with the credentials read from the environment.
+// FUSEBILL_TOKEN takes precedence over FUSEBILL_USERNAME and FUSEBILL_PASSWORD.
+func NewClientFromEnv(mode string, opts ...Option) (*Fusebill, error) {
+	credentials, err := credentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient(mode, credentials, opts...)
+	if err := client.Validate(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func credentialsFromEnv() (Credentials, error) {
+	if token := os.Getenv(EnvToken); token != "" {
+		return Credentials{Token: token}, nil
+	}
+
+	username, password := os.Getenv(EnvUsername), os.Getenv(EnvPassword)
+	if username == "" || password == "" {
+		return Credentials{}, errors.New(EnvToken + " or " + EnvUsername + " and " + EnvPassword + " should be set")
+	}
+
+	return Credentials{Username: username, Password: password}, nil
+}
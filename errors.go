@@ -0,0 +1,105 @@
+package fusebill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIError is returned when Fusebill responds with a non-successful status code
+type APIError struct {
+	StatusCode int
+	Body       []byte
+	Endpoint   string
+	Method     string
+	// RetryAfter is the delay requested by the Retry-After header, zero if it was absent
+	RetryAfter time.Duration
+	// Details are the errors listed by Fusebill, empty when Body is not the usual JSON error
+	Details []FieldError
+}
+
+// ReadError is returned when the body of a response could not be read in full, e.g. because
+// the connection dropped mid-stream. StatusCode is the status the response did arrive with.
+type ReadError struct {
+	StatusCode int
+	Endpoint   string
+	Method     string
+	// Body is the part of the body read before the failure
+	Body []byte
+	Err  error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("unable to read the body of the %d response from %s %s after %d bytes: %v", e.StatusCode, e.Method, e.Endpoint, len(e.Body), e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// ErrResponseTooLarge is wrapped in the *ReadError of a response body over Fusebill.MaxResponseBytes
+var ErrResponseTooLarge = errors.New("fusebill: response body too large")
+
+// FieldError is one of the errors listed in a Fusebill error response
+type FieldError struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ErrEmptyResponse is wrapped in the decode error of a call that needs a value but got no content
+var ErrEmptyResponse = errors.New("fusebill: empty response")
+
+// ErrSessionExpired is returned when a JSON endpoint answers with an HTML page, which is the
+// login page Fusebill serves with a 200 once the private API session has expired
+var ErrSessionExpired = errors.New("fusebill: session expired")
+
+// ErrUnauthorized matches, via errors.Is, an *APIError for a 401 response
+var ErrUnauthorized = errors.New("fusebill: unauthorized")
+
+// ErrNotFound matches, via errors.Is, an *APIError for a 404 response
+var ErrNotFound = errors.New("fusebill: not found")
+
+// Is makes errors.Is match ErrUnauthorized against a 401 and ErrNotFound against a 404
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+
+	return false
+}
+
+func newAPIError(resp *http.Response, body []byte, method, endpoint string) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, Body: body, Endpoint: endpoint, Method: method}
+	e.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	parsed := &struct {
+		Errors []FieldError `json:"errors"`
+	}{}
+	if json.Unmarshal(body, parsed) == nil {
+		e.Details = parsed.Errors
+	}
+
+	return e
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Request failed with the status code: %d, content: %s", e.StatusCode, string(e.Body))
+}
+
+// maxPreviewBytes limits how much of a response body is quoted in decode errors
+const maxPreviewBytes = 128
+
+// decodeError wraps err with the endpoint and the beginning of the body it failed to decode
+func decodeError(endpoint string, body []byte, err error) error {
+	preview := body
+	if len(preview) > maxPreviewBytes {
+		preview = preview[:maxPreviewBytes]
+	}
+
+	return fmt.Errorf("unable to decode response from %s: %w; body: %q", endpoint, err, preview)
+}
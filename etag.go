@@ -0,0 +1,35 @@
+package fusebill
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotModified is returned by Decode and the typed getters when a conditional request
+// was answered with 304 Not Modified, the data the caller cached is still current
+var ErrNotModified = errors.New("not modified")
+
+// etagCache holds the last ETag seen per endpoint
+type etagCache struct {
+	mu   sync.Mutex
+	tags map[string]string
+}
+
+func (c *etagCache) get(endpoint string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tags[endpoint]
+}
+
+func (c *etagCache) store(endpoint, tag string) {
+	if tag == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tags == nil {
+		c.tags = map[string]string{}
+	}
+	c.tags[endpoint] = tag
+}
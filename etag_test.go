@@ -0,0 +1,62 @@
+package fusebill
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestETagCache(t *testing.T) {
+	var c etagCache
+	if got := c.get("/customers/1"); got != "" {
+		t.Errorf("got %q from an empty cache", got)
+	}
+
+	steps := []struct {
+		store, tag string
+		get, want  string
+	}{
+		{"/customers/1", `"v1"`, "/customers/1", `"v1"`},
+		{"/customers/2", `"w1"`, "/customers/1", `"v1"`},
+		{"/customers/1", `"v2"`, "/customers/1", `"v2"`},
+		{"/customers/1", "", "/customers/1", `"v2"`},
+		{"/customers/3", "", "/customers/3", ""},
+	}
+
+	for _, s := range steps {
+		c.store(s.store, s.tag)
+		if got := c.get(s.get); got != s.want {
+			t.Errorf("after storing %q for %s got %q for %s, want %q", s.tag, s.store, got, s.get, s.want)
+		}
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	var ifNoneMatch []string
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+	f.ConditionalRequests = true
+
+	if _, err := f.GetCustomer("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.GetCustomer("1"); !errors.Is(err, ErrNotModified) {
+		t.Errorf("got %v, want ErrNotModified", err)
+	}
+	if _, err := f.UpdateCustomer("1", CustomerUpdate{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"", `"v1"`, ""}
+	if len(ifNoneMatch) != 3 || ifNoneMatch[0] != want[0] || ifNoneMatch[1] != want[1] || ifNoneMatch[2] != want[2] {
+		t.Errorf("sent If-None-Match %q, want %q", ifNoneMatch, want)
+	}
+}
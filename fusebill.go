@@ -2,96 +2,405 @@ package fusebill
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 type Invoice struct {
-	OutstandingBalance float64 `json:"outstandingBalance"`
+	Id                 int        `json:"id"`
+	InvoiceNumber      int        `json:"invoiceNumber"`
+	Status             string     `json:"status"`
+	InvoiceDate        Date       `json:"invoiceDate"`
+	DueDate            Date       `json:"dueDate"`
+	OutstandingBalance float64    `json:"outstandingBalance"`
+	LineItems          []LineItem `json:"lineItems"`
+}
+
+type LineItem struct {
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitPrice   float64 `json:"unitPrice"`
+	Amount      float64 `json:"amount"`
 }
 
 type WriteOff struct {
 	InvoiceId int     `json:"invoiceId"`
 	Amount    float64 `json:"amount"`
+	// Note records why the invoice was written off
+	Note string `json:"note,omitempty"`
+}
+
+// WriteOffResult is the write-off record created by Fusebill
+type WriteOffResult struct {
+	Id                 int     `json:"id"`
+	InvoiceId          int     `json:"invoiceId"`
+	Amount             float64 `json:"amount"`
+	OutstandingBalance float64 `json:"outstandingBalance"`
+}
+
+// Version of the package, bumped on release
+const Version = "0.1.0"
+
+// DefaultUserAgent identifies the client unless WithUserAgent is given
+const DefaultUserAgent = "aracoool-fusebill-go/" + Version
+
+func (f *Fusebill) userAgent() string {
+	if f.UserAgent == "" {
+		return DefaultUserAgent
+	}
+
+	return f.UserAgent
+}
+
+// setDefaultHeaders sets the User-Agent and f.Headers, the caller sets
+// Content-Type and Authorization afterwards so the defaults cannot clobber them
+func (f *Fusebill) setDefaultHeaders(h http.Header) {
+	h.Set("User-Agent", f.userAgent())
+	for k, v := range f.Headers {
+		h.Set(k, v)
+	}
+}
+
+// DefaultAccept is the Accept header of requests that do not set RequestDetails.Accept
+const DefaultAccept = "application/json"
+
+// IdempotencyKeyHeader carries RequestDetails.IdempotencyKey
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// newIdempotencyKey returns a random key for a mutation the caller gave no key for
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
 }
 
 type RequestDetails struct {
 	Method   string
 	Endpoint string
 	Body     io.Reader
+	// Payload, when set, is encoded as JSON and sent instead of Body
+	Payload interface{}
+	// IdempotencyKey, when set, lets Fusebill recognise a repeated mutation.
+	// Requests carrying a key are retried like idempotent ones.
+	IdempotencyKey string
+	// Accept is the media type asked for, e.g. "application/pdf".
+	// DefaultAccept is sent when it is empty and the client has no Accept default header.
+	Accept string
+	// Headers are applied last, so they override the client defaults and the standard headers
+	Headers http.Header
+	// Timeout, when set, bounds the call including its retries instead of Client.Timeout
+	// and Fusebill.EndpointTimeouts
+	Timeout time.Duration
+
+	// stream is set by SendRequestStream, whose body is returned unread
+	stream bool
 }
 
 type Response struct {
 	Body       []byte
 	StatusCode int
+	Headers    http.Header
+	// ContentType is the media type of the body without parameters, e.g. "application/pdf"
+	ContentType string
+	// NotModified is set when a conditional request was answered with 304, the body is empty
+	NotModified bool
+	// Duration is how long the call took, retries and reading the body included
+	Duration time.Duration
+}
+
+// IsEmpty reports whether the response has no content, i.e. it is a 204 or its body is blank
+func (r Response) IsEmpty() bool {
+	return r.StatusCode == http.StatusNoContent || len(bytes.TrimSpace(r.Body)) == 0
 }
 
+// Credentials authenticate the client. For the Basic scheme Token must be the
+// base64 encoded "username:password" pair, see NewBasicToken. When Token is empty
+// it is built from Username and Password.
 type Credentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Token    string
+	// Scheme is how Token is sent in the Authorization header, AuthBasic when empty
+	Scheme AuthScheme
 }
 
 type Fusebill struct {
-	BaseUrl     string
+	BaseUrl string
+	// Credentials must not be changed while requests are in flight, rotate the token with SetToken
 	Credentials Credentials
 	Client      *http.Client
-	cookieJar   *cookiejar.Jar
+	Retry       RetryConfig
+	// RefreshToken, when set, is called once to replace a token rejected with a 401
+	RefreshToken TokenRefresher
+	// LoginPath is the private API login endpoint relative to BaseUrl, DefaultLoginPath when empty
+	LoginPath string
+	// SessionTTL is how long a private API session is reused, DefaultSessionTTL when zero
+	SessionTTL time.Duration
+	// OnRequest and OnResponse, when set, are called around every HTTP call
+	OnRequest  RequestHook
+	OnResponse ResponseHook
+	// RedactFields are the JSON fields masked in the bodies passed to OnRequest,
+	// DefaultRedactFields when nil. An empty slice disables redaction.
+	RedactFields []string
+	// Observer, when set, is told the outcome of every request
+	Observer Observer
+	// RequestModifier, when set, can inspect or change every request, e.g. to sign it,
+	// just before it is sent. An error aborts the call.
+	RequestModifier func(*http.Request) error
+	// UserAgent is sent with every request, DefaultUserAgent when empty
+	UserAgent string
+	// Headers are sent with every request, they cannot override Content-Type or Authorization
+	Headers map[string]string
+	// DisableCompression stops the client from asking for gzip encoded responses
+	DisableCompression bool
+	// RateLimit throttles requests on the client side, unlimited when zero
+	RateLimit RateLimit
+	// DryRun records mutating requests, see DryRunRequests, instead of sending them.
+	// They are answered with an empty JSON object. Read requests are still sent.
+	DryRun bool
+	// EndpointTimeouts bound the calls to the endpoints starting with a prefix, e.g. "/reports",
+	// like RequestDetails.Timeout. The longest matching prefix wins, Client.Timeout applies otherwise.
+	EndpointTimeouts map[string]time.Duration
+	// Location is the time zone dates returned without an offset are read in and query
+	// dates are sent in, UTC when nil
+	Location *time.Location
+	// MaxResponseBytes limits the size of a buffered response body, DefaultMaxResponseBytes
+	// when zero. Larger responses fail with a *ReadError wrapping ErrResponseTooLarge.
+	// Bodies returned unread by SendRequestStream are not limited.
+	MaxResponseBytes int64
+	// ConditionalRequests caches the ETag of every GET response per endpoint and sends it
+	// back as If-None-Match. A 304 answer has NotModified set on its Response.
+	ConditionalRequests bool
+	// DisallowUnknownFields makes decoding fail on response fields the target type lacks,
+	// useful in tests to catch schema drift. Decoding is lenient by default.
+	DisallowUnknownFields bool
+
+	// mu guards the authentication state below
+	mu             sync.Mutex
+	cookieJar      *cookiejar.Jar
+	authGeneration uint64
+	sessionExpires time.Time
+	authCall       *authCall
+
+	bucket     tokenBucket
+	transport  *http.Transport // owned by the client, the only transport Close releases
+	modeErr    error
+	optionErr  error
+	apiVersion string
+
+	// httpClient and the client and transport options are recorded by the options, see configureClient
+	httpClient       *http.Client
+	clientOptions    []func(*http.Client)
+	transportOptions []func(*http.Transport)
+
+	dryRunMu       sync.Mutex
+	dryRunRequests []DryRunRequest
+
+	etags etagCache
 }
 
-var mux sync.Mutex
+const writeOffEndpoint = "/api/invoices/writeoff"
 
 // WriteOff writes a invoice off
 func (f *Fusebill) WriteOff(invoiceID string, balance float64) error {
-	if balance <= 0 {
-		return errors.New(fmt.Sprintf("Invoice %s: outstandingBalance is %.2f", invoiceID, balance))
+	return f.WriteOffContext(context.Background(), invoiceID, balance)
+}
+
+// WriteOffContext writes a invoice off using the ctx for cancellation.
+// As with SendRequestContext, Client.Timeout still bounds the call. To avoid writing
+// the invoice off twice it is only retried when the connection could not be established.
+func (f *Fusebill) WriteOffContext(ctx context.Context, invoiceID string, balance float64) error {
+	return f.WriteOffWithKeyContext(ctx, invoiceID, balance, "")
+}
+
+// WriteOffWithKey writes a invoice off sending the idempotency key
+func (f *Fusebill) WriteOffWithKey(invoiceID string, balance float64, key string) error {
+	return f.WriteOffWithKeyContext(context.Background(), invoiceID, balance, key)
+}
+
+// WriteOffWithKeyContext writes a invoice off sending the idempotency key, using the ctx for cancellation.
+// With a non-empty key the write-off is retried like an idempotent request.
+func (f *Fusebill) WriteOffWithKeyContext(ctx context.Context, invoiceID string, balance float64, key string) error {
+	_, err := f.writeOff(ctx, invoiceID, balance, "", key)
+	return err
+}
+
+// WriteOffWithNote writes a invoice off recording the reason in its note
+func (f *Fusebill) WriteOffWithNote(invoiceID string, balance float64, note string) error {
+	return f.WriteOffWithNoteContext(context.Background(), invoiceID, balance, note)
+}
+
+// WriteOffWithNoteContext writes a invoice off recording the reason in its note, using the ctx for cancellation.
+// The note is required.
+func (f *Fusebill) WriteOffWithNoteContext(ctx context.Context, invoiceID string, balance float64, note string) error {
+	if strings.TrimSpace(note) == "" {
+		return errors.New(fmt.Sprintf("Invoice %s: write-off note is empty", invoiceID))
+	}
+
+	_, err := f.writeOff(ctx, invoiceID, balance, note, "")
+	return err
+}
+
+// ErrExceedsBalance is wrapped by the error WriteOffPartial returns when the amount exceeds the outstanding balance
+var ErrExceedsBalance = errors.New("amount exceeds the outstanding balance")
+
+// WriteOffPartial writes the amount of a invoice off, with validate it is first checked against the outstanding balance
+func (f *Fusebill) WriteOffPartial(invoiceID string, amount float64, validate bool) error {
+	return f.WriteOffPartialContext(context.Background(), invoiceID, amount, validate)
+}
+
+// WriteOffPartialContext writes the amount of a invoice off using the ctx for cancellation.
+// With validate the outstanding balance is fetched first, an amount above it fails
+// with an error wrapping ErrExceedsBalance and nothing is written off.
+func (f *Fusebill) WriteOffPartialContext(ctx context.Context, invoiceID string, amount float64, validate bool) error {
+	if validate {
+		balance, err := f.GetInvoiceBalanceContext(ctx, invoiceID)
+		if err != nil {
+			return err
+		}
+
+		if amount > balance {
+			return fmt.Errorf("Invoice %s: %w, %.2f > %.2f", invoiceID, ErrExceedsBalance, amount, balance)
+		}
 	}
 
-	mux.Lock()
-	err := f.login()
+	_, err := f.writeOff(ctx, invoiceID, amount, "", "")
+	return err
+}
+
+// WriteOffWithResult writes a invoice off and returns the created write-off
+func (f *Fusebill) WriteOffWithResult(invoiceID string, balance float64) (*WriteOffResult, error) {
+	return f.WriteOffWithResultContext(context.Background(), invoiceID, balance)
+}
+
+// WriteOffWithResultContext writes a invoice off and returns the created write-off, using the ctx for cancellation
+func (f *Fusebill) WriteOffWithResultContext(ctx context.Context, invoiceID string, balance float64) (*WriteOffResult, error) {
+	resp, err := f.writeOff(ctx, invoiceID, balance, "", "")
 	if err != nil {
-		mux.Unlock()
-		return err
+		return nil, err
 	}
-	mux.Unlock()
 
-	i, _ := strconv.Atoi(invoiceID)
-	data := &WriteOff{InvoiceId: i, Amount: balance}
-	r, _ := json.Marshal(data)
+	if err := requireContent(writeOffEndpoint, resp); err != nil {
+		return nil, err
+	}
+
+	result := &WriteOffResult{}
+	if err := f.decodeResponse(writeOffEndpoint, resp, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type writeOffReversal struct {
+	WriteOffId int `json:"writeOffId"`
+}
 
-	request, err := http.NewRequest("POST", f.BaseUrl+"/api/invoices/writeoff", bytes.NewBuffer(r))
+const reverseWriteOffEndpoint = "/api/invoices/writeoff/reverse"
+
+// ReverseWriteOff reverses a write-off applied in error
+func (f *Fusebill) ReverseWriteOff(writeOffID string) error {
+	_, err := f.reverseWriteOff(context.Background(), writeOffID)
+	return err
+}
+
+// ReverseWriteOffContext reverses a write-off applied in error using the ctx for cancellation
+func (f *Fusebill) ReverseWriteOffContext(ctx context.Context, writeOffID string) error {
+	_, err := f.reverseWriteOff(ctx, writeOffID)
+	return err
+}
+
+// ReverseWriteOffWithResult reverses a write-off and returns it along with the restored invoice balance
+func (f *Fusebill) ReverseWriteOffWithResult(writeOffID string) (*WriteOffResult, error) {
+	return f.ReverseWriteOffWithResultContext(context.Background(), writeOffID)
+}
+
+// ReverseWriteOffWithResultContext reverses a write-off and returns it along with the restored invoice
+// balance, using the ctx for cancellation
+func (f *Fusebill) ReverseWriteOffWithResultContext(ctx context.Context, writeOffID string) (*WriteOffResult, error) {
+	resp, err := f.reverseWriteOff(ctx, writeOffID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
+	if err := requireContent(reverseWriteOffEndpoint, resp); err != nil {
+		return nil, err
+	}
 
-	resp, err := f.Client.Do(request)
+	result := &WriteOffResult{}
+	if err := f.decodeResponse(reverseWriteOffEndpoint, resp, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (f *Fusebill) reverseWriteOff(ctx context.Context, writeOffID string) (Response, error) {
+	i, err := parseID("Write-off", writeOffID)
 	if err != nil {
-		return err
+		return Response{}, err
 	}
 
-	defer resp.Body.Close()
+	return f.sendPrivate(ctx, RequestDetails{Method: "POST", Endpoint: reverseWriteOffEndpoint, Payload: &writeOffReversal{WriteOffId: i}})
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("server returns %d; message %s", resp.StatusCode, body)
+func (f *Fusebill) writeOff(ctx context.Context, invoiceID string, balance float64, note, key string) (Response, error) {
+	if balance <= 0 {
+		return Response{}, errors.New(fmt.Sprintf("Invoice %s: outstandingBalance is %.2f", invoiceID, balance))
 	}
 
-	return nil
+	i, err := parseID("Invoice", invoiceID)
+	if err != nil {
+		return Response{}, err
+	}
+	data := &WriteOff{InvoiceId: i, Amount: balance, Note: note}
+
+	return f.sendPrivate(ctx, RequestDetails{Method: "POST", Endpoint: writeOffEndpoint, Payload: data, IdempotencyKey: key})
+}
+
+// parseID converts the id of the kind of resource, e.g. "Invoice", for a request body.
+// An id that is not a number fails rather than being sent as 0.
+func parseID(kind, id string) (int, error) {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("%s id %q is not a number", kind, id)
+	}
+
+	return i, nil
+}
+
+// DefaultLoginPath is the private API login endpoint used when LoginPath is empty
+const DefaultLoginPath = "/api/Login/"
+
+func (f *Fusebill) loginPath() string {
+	if f.LoginPath == "" {
+		return DefaultLoginPath
+	}
+
+	return f.LoginPath
 }
 
 // Login user
-func (f *Fusebill) login() error {
+func (f *Fusebill) login(ctx context.Context) error {
+	if f.modeErr != nil {
+		return f.modeErr
+	}
 	if f.cookieJar == nil {
 		return errors.New("cookie jar should be set")
 	}
@@ -100,96 +409,591 @@ func (f *Fusebill) login() error {
 	data.Add("username", f.Credentials.Username)
 	data.Add("password", f.Credentials.Password)
 
-	resp, err := f.Client.PostForm(f.BaseUrl+"/api/Login/", data)
+	request, err := http.NewRequestWithContext(ctx, "POST", f.BaseUrl+f.loginPath(), strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+
+	f.setDefaultHeaders(request.Header)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := f.Client.Do(request)
 	if err != nil {
 		return err
 	}
 
 	defer resp.Body.Close()
-	_, _ = ioutil.ReadAll(resp.Body)
+	body, err := f.readBody(resp.Body)
+	if err != nil {
+		return &ReadError{StatusCode: resp.StatusCode, Endpoint: f.loginPath(), Method: "POST", Body: body, Err: err}
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		return errors.New("unable to login, check you login and password")
+	if !isSuccess(resp.StatusCode) {
+		return newAPIError(resp, body, "POST", f.loginPath())
 	}
 
 	return nil
 }
 
+// GetInvoiceBalance returns the outstanding balance of the invoice
 func (f *Fusebill) GetInvoiceBalance(invoiceID string) (float64, error) {
-	resp, err := f.SendRequest(RequestDetails{"GET", "/invoices/" + invoiceID, nil})
+	return f.GetInvoiceBalanceContext(context.Background(), invoiceID)
+}
+
+// GetInvoiceBalanceContext returns the outstanding balance of the invoice using the ctx for cancellation
+func (f *Fusebill) GetInvoiceBalanceContext(ctx context.Context, invoiceID string) (float64, error) {
+	invoice, err := f.GetInvoiceContext(ctx, invoiceID)
 	if err != nil {
 		return 0, err
 	}
 
-	invoice := &Invoice{}
-	_ = json.Unmarshal(resp.Body, invoice)
 	return invoice.OutstandingBalance, nil
 }
 
-//SendRequest sends request to the specific endpoint
+// SendRequest sends request to the specific endpoint
 func (f *Fusebill) SendRequest(r RequestDetails) (Response, error) {
-	request, err := http.NewRequest(r.Method, f.BaseUrl+r.Endpoint, r.Body)
+	return f.SendRequestContext(context.Background(), r)
+}
+
+// SendRequestContext sends request to the specific endpoint using the ctx for cancellation.
+// Client.Timeout still applies, so the call is aborted by whichever of the ctx deadline
+// and Client.Timeout expires first. Failed requests are retried according to f.Retry.
+// A 401 makes the client re-authenticate, or pick up a token given to SetToken in the
+// meantime, and send the request once more.
+func (f *Fusebill) SendRequestContext(ctx context.Context, r RequestDetails) (Response, error) {
+	return f.receive(ctx, r, false)
+}
+
+// Decode sends request to the specific endpoint and unmarshals the JSON response into out
+func (f *Fusebill) Decode(r RequestDetails, out interface{}) error {
+	return f.DecodeContext(context.Background(), r, out)
+}
+
+// DecodeContext sends request to the specific endpoint and unmarshals the JSON response into out,
+// using the ctx for cancellation. An empty response body leaves out untouched.
+func (f *Fusebill) DecodeContext(ctx context.Context, r RequestDetails, out interface{}) error {
+	resp, err := f.SendRequestContext(ctx, r)
 	if err != nil {
-		return Response{}, err
+		return err
 	}
 
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Authorization", "Basic "+f.Credentials.Token)
+	return f.decodeResponse(r.Endpoint, resp, out)
+}
 
-	resp, err := f.Client.Do(request)
+// decodeValue sends the request and decodes the value the call needs into out, an empty
+// response is reported as a decode error wrapping ErrEmptyResponse rather than a zero value
+func (f *Fusebill) decodeValue(ctx context.Context, r RequestDetails, out interface{}) error {
+	resp, err := f.SendRequestContext(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := requireContent(r.Endpoint, resp); err != nil {
+		return err
+	}
+
+	return f.decodeResponse(r.Endpoint, resp, out)
+}
+
+// requireContent checks that the response carries the value a call needs. A 304 is reported
+// as ErrNotModified, an empty body as a decode error wrapping ErrEmptyResponse.
+func requireContent(endpoint string, resp Response) error {
+	if resp.NotModified {
+		return ErrNotModified
+	}
+	if resp.IsEmpty() {
+		return decodeError(endpoint, resp.Body, ErrEmptyResponse)
+	}
+
+	return nil
+}
+
+// decodeResponse unmarshals the response body into out, rejecting unknown fields when
+// f.DisallowUnknownFields is set, and moves its dates to f.Location
+func (f *Fusebill) decodeResponse(endpoint string, resp Response, out interface{}) error {
+	if err := decodeBody(endpoint, resp, out, f.DisallowUnknownFields); err != nil {
+		return err
+	}
+	if !resp.IsEmpty() {
+		localizeDates(out, f.location())
+	}
+
+	return nil
+}
+
+// decodeBody unmarshals the response body into out, reporting failures with the endpoint.
+// An empty response leaves out untouched, callers needing a value check for it themselves.
+// Binary bodies such as PDF or CSV exports are rejected instead of being fed to the JSON decoder.
+func decodeBody(endpoint string, resp Response, out interface{}, strict bool) error {
+	if resp.NotModified {
+		return ErrNotModified
+	}
+	if resp.IsEmpty() {
+		return nil
+	}
+	if resp.ContentType == "text/html" {
+		return decodeError(endpoint, resp.Body, ErrSessionExpired)
+	}
+	if !isJSON(resp.ContentType) {
+		return decodeError(endpoint, resp.Body, fmt.Errorf("unexpected content type %q", resp.ContentType))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(resp.Body))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(out); err != nil {
+		return decodeError(endpoint, resp.Body, err)
+	}
+
+	return nil
+}
+
+// SendRequestStream sends request to the specific endpoint and returns the unread response body.
+// The caller must close the body.
+func (f *Fusebill) SendRequestStream(r RequestDetails) (io.ReadCloser, int, error) {
+	return f.SendRequestStreamContext(context.Background(), r)
+}
+
+// SendRequestStreamContext is SendRequestStream using the ctx for cancellation.
+// The ctx must stay alive until the body has been read.
+func (f *Fusebill) SendRequestStreamContext(ctx context.Context, r RequestDetails) (io.ReadCloser, int, error) {
+	r.stream = true
+	resp, err := f.send(ctx, r, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return resp.Body, resp.StatusCode, nil
+}
+
+// DeleteResource deletes the resource at the endpoint
+func (f *Fusebill) DeleteResource(endpoint string) error {
+	return f.DeleteResourceContext(context.Background(), endpoint)
+}
+
+// DeleteResourceContext deletes the resource at the endpoint using the ctx for cancellation.
+// Any 2xx, typically 200 or 204, is a success, other statuses are returned as *APIError.
+func (f *Fusebill) DeleteResourceContext(ctx context.Context, endpoint string) error {
+	_, err := f.SendRequestContext(ctx, RequestDetails{Method: "DELETE", Endpoint: endpoint})
+	return err
+}
+
+// Patch sends the fields as a partial update of the resource at the endpoint
+func (f *Fusebill) Patch(endpoint string, fields map[string]interface{}) (Response, error) {
+	return f.PatchContext(context.Background(), endpoint, fields)
+}
+
+// PatchContext sends the fields as a partial update of the resource at the endpoint using the ctx for cancellation
+func (f *Fusebill) PatchContext(ctx context.Context, endpoint string, fields map[string]interface{}) (Response, error) {
+	return f.SendRequestContext(ctx, RequestDetails{Method: "PATCH", Endpoint: endpoint, Payload: fields})
+}
+
+// sendPrivate sends a request to the cookie authenticated private API, logging in
+// first when needed and once more if the session turns out to be expired.
+func (f *Fusebill) sendPrivate(ctx context.Context, r RequestDetails) (Response, error) {
+	return f.receive(ctx, r, true)
+}
+
+// receive sends the request and buffers the response, timing the whole call
+func (f *Fusebill) receive(ctx context.Context, r RequestDetails, private bool) (Response, error) {
+	start := time.Now()
+	httpResp, err := f.send(ctx, r, private)
+	if err != nil {
+		return Response{}, err
+	}
+
+	resp, err := f.readResponse(r, httpResp)
 	if err != nil {
 		return Response{}, err
 	}
+
+	resp.Duration = time.Since(start)
+	return resp, nil
+}
+
+// readResponse buffers the body of a response returned by send
+func (f *Fusebill) readResponse(r RequestDetails, resp *http.Response) (Response, error) {
 	defer resp.Body.Close()
 
-	if resp.StatusCode > http.StatusNoContent {
-		content, _ := ioutil.ReadAll(resp.Body)
-		return Response{}, errors.New(fmt.Sprintf("Request failed with the status code: %d, content: %s", resp.StatusCode, string(content)))
+	b, err := f.readBody(resp.Body)
+	if err != nil {
+		return Response{}, &ReadError{StatusCode: resp.StatusCode, Endpoint: r.Endpoint, Method: r.Method, Body: b, Err: err}
 	}
+	if b == nil {
+		b = []byte{}
+	}
+
+	return Response{
+		Body:        b,
+		StatusCode:  resp.StatusCode,
+		Headers:     resp.Header,
+		ContentType: mediaType(resp.Header),
+		NotModified: resp.StatusCode == http.StatusNotModified,
+	}, nil
+}
+
+// DefaultMaxResponseBytes is the response body size limit used when MaxResponseBytes is zero
+const DefaultMaxResponseBytes = 10 << 20
+
+func (f *Fusebill) maxResponseBytes() int64 {
+	if f.MaxResponseBytes <= 0 {
+		return DefaultMaxResponseBytes
+	}
+
+	return f.MaxResponseBytes
+}
 
-	b, err := ioutil.ReadAll(resp.Body)
+// readBody reads body in full unless it exceeds the size limit, in which case the bytes
+// up to the limit are returned with ErrResponseTooLarge
+func (f *Fusebill) readBody(body io.Reader) ([]byte, error) {
+	limit := f.maxResponseBytes()
+	b, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err == nil && int64(len(b)) > limit {
+		return b[:limit], ErrResponseTooLarge
+	}
+
+	return b, err
+}
+
+// mediaType returns the Content-Type of h without parameters, lower-cased
+func mediaType(h http.Header) string {
+	value := h.Get("Content-Type")
+	if t, _, err := mime.ParseMediaType(value); err == nil {
+		return t
+	}
+
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// isJSON reports whether a body of the media type may be decoded as JSON. Text and
+// unlabelled bodies are accepted since servers commonly mislabel JSON as text/plain.
+func isJSON(contentType string) bool {
+	return contentType == "" || strings.HasPrefix(contentType, "text/") ||
+		contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+// body returns the encoded Payload, or the content of Body when there is no Payload
+func (r RequestDetails) body() ([]byte, error) {
+	if r.Payload != nil {
+		return json.Marshal(r.Payload)
+	}
+
+	if r.Body != nil {
+		return io.ReadAll(r.Body)
+	}
+
+	return nil, nil
+}
+
+// send performs the request and returns the successful response with its body unread.
+// Private requests are authenticated by the session cookie instead of the token.
+func (f *Fusebill) send(ctx context.Context, r RequestDetails, private bool) (*http.Response, error) {
+	timeout := f.timeout(r)
+	if timeout <= 0 {
+		return f.sendWith(ctx, f.Client, r, private)
+	}
+
+	// The deadline replaces Client.Timeout, so it is dropped from a copy of the client
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	client := *f.Client
+	client.Timeout = 0
+
+	resp, err := f.sendWith(ctx, &client, r, private)
 	if err != nil {
-		return Response{}, err
+		cancel()
+		return nil, err
 	}
 
-	return Response{Body: b, StatusCode: resp.StatusCode}, nil
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
 }
 
-// NewClient returns the new fusebill client
-func NewClient(mode string, credentials Credentials) *Fusebill {
-	var baseUrl string
-	if mode == "production" {
-		baseUrl = "https://secure.fusebill.com/v1"
-	} else {
-		baseUrl = "https://stg-secure.fusebill.com/v1"
+// timeout returns the timeout of the request: its own, else the one registered for the
+// longest prefix of its endpoint in EndpointTimeouts, else zero for Client.Timeout
+func (f *Fusebill) timeout(r RequestDetails) time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
 	}
 
-	return &Fusebill{
-		BaseUrl:     baseUrl,
-		Credentials: credentials,
-		Client: &http.Client{
-			Timeout: time.Second * 5,
-		},
+	var timeout time.Duration
+	matched := -1
+	for prefix, d := range f.EndpointTimeouts {
+		if len(prefix) > matched && strings.HasPrefix(r.Endpoint, prefix) {
+			timeout, matched = d, len(prefix)
+		}
 	}
+
+	return timeout
 }
 
-// NewPrivateClient returns the new fusebill client for the Private API
-func NewPrivateClient(mode string, credentials Credentials) *Fusebill {
-	var baseUrl string
-	if mode == "production" {
-		baseUrl = "https://secure.fusebill.com"
+// cancelOnClose releases the context of a request once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+func (f *Fusebill) sendWith(ctx context.Context, client *http.Client, r RequestDetails, private bool) (*http.Response, error) {
+	if f.modeErr != nil {
+		return nil, f.modeErr
+	}
+
+	body, err := r.body()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.DryRun && isMutation(r.Method) {
+		return f.recordDryRun(r, body), nil
+	}
+
+	conditional := f.ConditionalRequests && r.Method == http.MethodGet
+	newRequest := func() (*http.Request, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+
+		request, err := http.NewRequestWithContext(ctx, r.Method, f.BaseUrl+r.Endpoint, reader)
+		if err != nil {
+			return nil, err
+		}
+
+		f.setDefaultHeaders(request.Header)
+		if body != nil {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		if r.Accept != "" {
+			request.Header.Set("Accept", r.Accept)
+		} else if request.Header.Get("Accept") == "" {
+			request.Header.Set("Accept", DefaultAccept)
+		}
+		if !f.DisableCompression {
+			request.Header.Set("Accept-Encoding", "gzip")
+		}
+		if !private {
+			request.Header.Set("Authorization", f.authorization())
+		}
+		if r.IdempotencyKey != "" {
+			request.Header.Set(IdempotencyKeyHeader, r.IdempotencyKey)
+		}
+		if conditional {
+			if tag := f.etags.get(r.Endpoint); tag != "" {
+				request.Header.Set("If-None-Match", tag)
+			}
+		}
+		for k, values := range r.Headers {
+			request.Header.Del(k)
+			for _, v := range values {
+				request.Header.Add(k, v)
+			}
+		}
+
+		if f.RequestModifier != nil {
+			if err := f.RequestModifier(request); err != nil {
+				return nil, err
+			}
+		}
+		return request, nil
+	}
+
+	idempotent := isIdempotent(r.Method) || r.IdempotencyKey != ""
+
+	var generation uint64
+	if private {
+		if generation, err = f.ensureSession(ctx); err != nil {
+			return nil, err
+		}
 	} else {
-		baseUrl = "https://stg-secure.fusebill.com"
+		generation = f.generation()
+	}
+
+	start := time.Now()
+	resp, err := f.do(ctx, client, newRequest, idempotent, r.stream)
+	if err == nil && f.isAuthRejected(resp) && (f.canReauthenticate() || f.generation() != generation) {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := f.reauthenticate(ctx, generation); err != nil {
+			return nil, err
+		}
+
+		resp, err = f.do(ctx, client, newRequest, idempotent, r.stream)
+	}
+	f.observe(r.Endpoint, resp, start)
+	if err != nil {
+		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+	if !isSuccess(resp.StatusCode) {
+		defer resp.Body.Close()
+		content, err := f.readBody(resp.Body)
+		if err != nil {
+			return nil, &ReadError{StatusCode: resp.StatusCode, Endpoint: r.Endpoint, Method: r.Method, Body: content, Err: err}
+		}
+		return nil, newAPIError(resp, content, r.Method, r.Endpoint)
+	}
+	if f.cookieJar != nil && isLoginPage(resp) {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %w", r.Method, r.Endpoint, ErrSessionExpired)
+	}
+	if conditional {
+		f.etags.store(r.Endpoint, resp.Header.Get("ETag"))
+	}
+
+	return resp, nil
+}
+
+// Ping checks connectivity and credentials with a cheap read-only request
+func (f *Fusebill) Ping() error {
+	return f.PingContext(context.Background())
+}
+
+// PingContext checks connectivity and credentials using the ctx for cancellation.
+// Rejected credentials are reported as an *APIError matching ErrUnauthorized, any other
+// status as an *APIError and a failure to reach Fusebill as a wrapped transport error.
+func (f *Fusebill) PingContext(ctx context.Context) error {
+	_, err := f.SendRequestContext(ctx, RequestDetails{Method: "GET", Endpoint: "/customers?pageSize=1"})
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return err
+	}
+
+	return fmt.Errorf("unable to reach fusebill: %w", err)
+}
+
+// Close releases the idle connections of the client and, for private clients, drops
+// the session cookie. The client stays usable, later requests open new connections
+// and log in again. Close must not be called while requests are in flight.
+// The connections of a transport given with WithHTTPClient are left to its owner.
+func (f *Fusebill) Close() {
+	if f.transport != nil && f.Client.Transport == http.RoundTripper(f.transport) {
+		f.transport.CloseIdleConnections()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cookieJar != nil {
+		f.cookieJar, _ = cookiejar.New(nil)
+		f.Client.Jar = f.cookieJar
+		f.sessionExpires = time.Time{}
+	}
+}
+
+// Validate reports configuration errors that would otherwise surface as confusing request failures
+func (f *Fusebill) Validate() error {
+	if f.modeErr != nil {
+		return f.modeErr
+	}
+	if f.optionErr != nil {
+		return f.optionErr
+	}
+
+	if f.BaseUrl == "" {
+		return errors.New("base url should be set")
+	}
+
+	u, err := url.Parse(f.BaseUrl)
+	if err != nil {
+		return fmt.Errorf("base url %q is malformed: %w", f.BaseUrl, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("base url %q should be an absolute http(s) url", f.BaseUrl)
+	}
+
+	if f.Client == nil {
+		return errors.New("http client should be set")
+	}
+
+	if f.cookieJar != nil {
+		if f.Credentials.Username == "" || f.Credentials.Password == "" {
+			return errors.New("username and password should be set for the private API")
+		}
+	} else if f.Credentials.Token == "" && f.RefreshToken == nil {
+		basic := f.Credentials.Scheme == "" || f.Credentials.Scheme == AuthBasic
+		if !basic || f.Credentials.Username == "" || f.Credentials.Password == "" {
+			return errors.New("token or username and password should be set")
+		}
+	}
+
+	return nil
+}
+
+// isSuccess reports whether the status code is in the 2xx range
+func isSuccess(code int) bool {
+	return code >= http.StatusOK && code < http.StatusMultipleChoices
+}
+
+// Modes select the Fusebill environment in NewClient and NewPrivateClient
+const (
+	ModeProduction = "production"
+	ModeStaging    = "staging"
+)
+
+// baseURL returns the base url of the mode, it reports false for an unknown mode
+func baseURL(mode string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case ModeProduction:
+		return "https://secure.fusebill.com", true
+	case ModeStaging:
+		return "https://stg-secure.fusebill.com", true
+	}
+
+	return "https://stg-secure.fusebill.com", false
+}
+
+// newClient builds the client for the mode. Unless an option replaced the base url, an
+// unknown mode fails every request and is reported by Validate, rather than silently
+// sending the traffic to staging.
+func newClient(mode, path string, credentials Credentials, opts []Option) *Fusebill {
+	base, known := baseURL(mode)
+
 	client := &Fusebill{
-		BaseUrl:     baseUrl,
+		BaseUrl:     base + path,
 		Credentials: credentials,
-		Client: &http.Client{
-			Timeout: time.Second * 5,
-		},
 	}
 
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.configureClient()
+
+	if client.BaseUrl != base+path {
+		return client
+	}
+	if !known {
+		client.modeErr = fmt.Errorf("unknown mode %q, expected %q or %q", mode, ModeProduction, ModeStaging)
+	}
+	if client.apiVersion != "" {
+		client.BaseUrl = base + "/" + client.apiVersion
+	}
+
+	return client
+}
+
+// NewClient returns the new fusebill client, mode is ModeProduction or ModeStaging in any case.
+// With any other mode every request fails, see Validate.
+func NewClient(mode string, credentials Credentials, opts ...Option) *Fusebill {
+	return newClient(mode, "/v1", credentials, opts)
+}
+
+// NewPrivateClient returns the new fusebill client for the Private API
+func NewPrivateClient(mode string, credentials Credentials, opts ...Option) *Fusebill {
+	client := newClient(mode, "", credentials, opts)
+
 	client.cookieJar, _ = cookiejar.New(nil)
 	client.Client.Jar = client.cookieJar
 
@@ -0,0 +1,49 @@
+package fusebill
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNonNumericIDIsNotSent(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(f *Fusebill) error
+	}{
+		{"WriteOff", func(f *Fusebill) error { return f.WriteOff("abc", 5) }},
+		{"Refund", func(f *Fusebill) error { _, err := f.Refund("", 5); return err }},
+		{"CreateCredit", func(f *Fusebill) error { _, err := f.CreateCredit("abc", 5, "goodwill"); return err }},
+		{"ApplyCredit credit", func(f *Fusebill) error { return f.ApplyCredit("abc", "1", 5) }},
+		{"ApplyCredit invoice", func(f *Fusebill) error { return f.ApplyCredit("1", "1.5", 5) }},
+		{"ReverseWriteOff", func(f *Fusebill) error { return f.ReverseWriteOff("abc") }},
+		{"CancelSubscription", func(f *Fusebill) error { return f.CancelSubscription("abc", CancelOptions{}) }},
+		{"TriggerCollection", func(f *Fusebill) error { return f.TriggerCollection("abc") }},
+		{"SendInvoiceEmail", func(f *Fusebill) error { return f.SendInvoiceEmail("abc", nil) }},
+		{"CreateDraftInvoice", func(f *Fusebill) error { _, err := f.CreateDraftInvoice("abc"); return err }},
+		{"GetCustomerFinancialSummary", func(f *Fusebill) error { _, err := f.GetCustomerFinancialSummary("abc"); return err }},
+		{"ApplyPayment", func(f *Fusebill) error {
+			return f.ApplyPayment("abc", []InvoiceAllocation{{InvoiceId: 1, Amount: 5}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			f, server := NewTestPrivateClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != DefaultLoginPath {
+					atomic.AddInt32(&requests, 1)
+				}
+				w.Write([]byte(`{"id":1}`))
+			}))
+			defer server.Close()
+
+			if err := tt.call(f); err == nil {
+				t.Error("a non-numeric id was accepted")
+			}
+			if n := atomic.LoadInt32(&requests); n != 0 {
+				t.Errorf("sent %d requests, want none", n)
+			}
+		})
+	}
+}
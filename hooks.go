@@ -0,0 +1,169 @@
+package fusebill
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Observer receives the endpoint, final status code and duration of every request,
+// e.g. to feed metrics. The status code is zero when no response was received.
+// Retries and re-authentication are included in the duration.
+type Observer interface {
+	ObserveRequest(endpoint string, status int, dur time.Duration)
+}
+
+// RequestHook is called before a request is sent. Headers, which carry the
+// credentials, are not passed, and the fields of Fusebill.RedactFields are masked in body.
+type RequestHook func(method, url string, body []byte)
+
+// DefaultRedactFields are masked in the bodies passed to OnRequest when Fusebill.RedactFields is nil
+var DefaultRedactFields = []string{"password", "cardNumber", "cvv", "token"}
+
+// redactedValue replaces the value of a redacted field
+const redactedValue = "[REDACTED]"
+
+func (f *Fusebill) redactFields() []string {
+	if f.RedactFields == nil {
+		return DefaultRedactFields
+	}
+
+	return f.RedactFields
+}
+
+// redact masks the JSON fields named in fields, matched case-insensitively at any depth.
+// A body that is not JSON or has none of the fields is returned unchanged.
+func redact(body []byte, fields []string) []byte {
+	if len(fields) == 0 || len(body) == 0 {
+		return body
+	}
+
+	var v interface{}
+	if json.Unmarshal(body, &v) != nil || !redactValue(v, fields) {
+		return body
+	}
+
+	redacted, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+
+	return redacted
+}
+
+// redactValue masks the fields in v in place and reports whether any was found
+func redactValue(v interface{}, fields []string) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isRedacted(key, fields) {
+				v[key] = redactedValue
+				found = true
+			} else if redactValue(value, fields) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if redactValue(value, fields) {
+				found = true
+			}
+		}
+	}
+
+	return found
+}
+
+func isRedacted(key string, fields []string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResponseHook is called once a response arrives, with a zero status code when
+// the request failed without one. The body is nil for SendRequestStream, whose
+// body is returned to the caller unread.
+type ResponseHook func(statusCode int, body []byte, elapsed time.Duration)
+
+// roundTrip sends a single request through client, invoking the hooks around it.
+// When OnResponse is set the response body is buffered so the hook can see it,
+// unless stream is set.
+func (f *Fusebill) roundTrip(client *http.Client, request *http.Request, stream bool) (*http.Response, error) {
+	if f.OnRequest != nil {
+		var body []byte
+		if request.GetBody != nil {
+			if rc, err := request.GetBody(); err == nil {
+				body, _ = io.ReadAll(rc)
+				rc.Close()
+			}
+		}
+		f.OnRequest(request.Method, request.URL.String(), redact(body, f.redactFields()))
+	}
+
+	start := time.Now()
+	resp, err := client.Do(request)
+	if err == nil {
+		decompress(resp)
+	}
+
+	if f.OnResponse == nil {
+		return resp, err
+	}
+
+	if err != nil {
+		f.OnResponse(0, nil, time.Since(start))
+		return resp, err
+	}
+	if stream {
+		f.OnResponse(resp.StatusCode, nil, time.Since(start))
+		return resp, nil
+	}
+
+	body, err := f.readBody(resp.Body)
+	resp.Body.Close()
+	f.OnResponse(resp.StatusCode, body, time.Since(start))
+
+	// a failed read is replayed after the body so the caller reports it with the status
+	reader := io.Reader(bytes.NewReader(body))
+	if err != nil {
+		reader = io.MultiReader(reader, failedReader{err})
+	}
+	resp.Body = io.NopCloser(reader)
+
+	return resp, nil
+}
+
+// failedReader fails every read with err
+type failedReader struct {
+	err error
+}
+
+func (r failedReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// observe reports the request to the Observer, stripping the query from the endpoint
+func (f *Fusebill) observe(endpoint string, resp *http.Response, start time.Time) {
+	if f.Observer == nil {
+		return
+	}
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+
+	f.Observer.ObserveRequest(endpoint, status, time.Since(start))
+}
@@ -0,0 +1,92 @@
+package fusebill
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnResponseLeavesStreamUnread(t *testing.T) {
+	payload := strings.Repeat("x", 2000)
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+	f.MaxResponseBytes = 1000
+
+	var hooked []byte
+	calls := 0
+	f.OnResponse = func(statusCode int, body []byte, elapsed time.Duration) {
+		calls++
+		hooked = body
+	}
+
+	body, status, err := f.SendRequestStream(RequestDetails{Method: "GET", Endpoint: "/invoices/1/pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	b, err := io.ReadAll(body)
+	if err != nil || string(b) != payload || status != http.StatusOK {
+		t.Errorf("got %d bytes, status %d and %v, want the 2000 bytes of the stream", len(b), status, err)
+	}
+	if calls != 1 || hooked != nil {
+		t.Errorf("hook called %d times with %d bytes, want once without the body", calls, len(hooked))
+	}
+
+	_, err = f.SendRequest(RequestDetails{Method: "GET", Endpoint: "/invoices/1/pdf"})
+	if !errors.Is(err, ErrResponseTooLarge) || len(hooked) != 1000 {
+		t.Errorf("got %v with %d bytes passed to the hook, want ErrResponseTooLarge with 1000", err, len(hooked))
+	}
+}
+
+func TestRedact(t *testing.T) {
+	fields := []string{"password", "cardNumber"}
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", ``, ``},
+		{"not JSON", `username=a&password=b`, `username=a&password=b`},
+		{"no field", `{"amount":5}`, `{"amount":5}`},
+		{"top level", `{"password":"secret","username":"a"}`, `{"password":"[REDACTED]","username":"a"}`},
+		{"case-insensitive", `{"PASSWORD":"secret"}`, `{"PASSWORD":"[REDACTED]"}`},
+		{"nested", `{"card":{"cardNumber":"4242"}}`, `{"card":{"cardNumber":"[REDACTED]"}}`},
+		{"in array", `[{"cardNumber":4242},{"cardNumber":null}]`, `[{"cardNumber":"[REDACTED]"},{"cardNumber":"[REDACTED]"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redact([]byte(tt.body), fields)); got != tt.want {
+				t.Errorf("redact(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+
+	if got := string(redact([]byte(`{"password":"secret"}`), nil)); got != `{"password":"secret"}` {
+		t.Errorf("got %s with no fields, want the body unchanged", got)
+	}
+}
+
+func TestOnRequestRedactsBody(t *testing.T) {
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var logged string
+	f.OnRequest = func(method, url string, body []byte) {
+		logged = string(body)
+	}
+
+	if _, err := f.SendRequest(RequestDetails{Method: "POST", Endpoint: "/paymentMethods", Payload: map[string]string{"token": "tok_1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if logged != `{"token":"[REDACTED]"}` {
+		t.Errorf("logged %s, want the token redacted", logged)
+	}
+}
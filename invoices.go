@@ -0,0 +1,414 @@
+package fusebill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// GetInvoice returns the invoice
+func (f *Fusebill) GetInvoice(invoiceID string) (*Invoice, error) {
+	return f.GetInvoiceContext(context.Background(), invoiceID)
+}
+
+// GetInvoiceContext returns the invoice using the ctx for cancellation.
+// A response without outstandingBalance is reported as a decode error rather
+// than read as a zero balance.
+func (f *Fusebill) GetInvoiceContext(ctx context.Context, invoiceID string) (*Invoice, error) {
+	endpoint := "/invoices/" + invoiceID
+	resp, err := f.SendRequestContext(ctx, RequestDetails{Method: "GET", Endpoint: endpoint})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireContent(endpoint, resp); err != nil {
+		return nil, err
+	}
+
+	invoice := &Invoice{}
+	if err := f.decodeResponse(endpoint, resp, invoice); err != nil {
+		return nil, err
+	}
+
+	balance := &struct {
+		OutstandingBalance *float64 `json:"outstandingBalance"`
+	}{}
+	if err := decodeBody(endpoint, resp, balance, false); err != nil {
+		return nil, err
+	}
+	if balance.OutstandingBalance == nil {
+		return nil, decodeError(endpoint, resp.Body, errors.New("outstandingBalance is missing"))
+	}
+
+	return invoice, nil
+}
+
+// GetInvoiceLineItems returns the line items of the invoice
+func (f *Fusebill) GetInvoiceLineItems(invoiceID string) ([]LineItem, error) {
+	return f.GetInvoiceLineItemsContext(context.Background(), invoiceID)
+}
+
+// GetInvoiceLineItemsContext returns the line items of the invoice using the ctx for cancellation.
+// They are embedded in the invoice, so this is GetInvoiceContext without the rest of it.
+func (f *Fusebill) GetInvoiceLineItemsContext(ctx context.Context, invoiceID string) ([]LineItem, error) {
+	invoice, err := f.GetInvoiceContext(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.LineItems == nil {
+		return []LineItem{}, nil
+	}
+
+	return invoice.LineItems, nil
+}
+
+// VoidInvoice voids the invoice entirely and returns it with its resulting status
+func (f *Fusebill) VoidInvoice(invoiceID string) (*Invoice, error) {
+	return f.VoidInvoiceContext(context.Background(), invoiceID)
+}
+
+// VoidInvoiceContext voids the invoice using the ctx for cancellation. A rejection, e.g. because
+// the invoice is already paid, is returned wrapping the *APIError with the server's message.
+// When the void response carries no invoice it is fetched again to report its status.
+func (f *Fusebill) VoidInvoiceContext(ctx context.Context, invoiceID string) (*Invoice, error) {
+	endpoint := "/invoices/" + invoiceID + "/void"
+	resp, err := f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: endpoint})
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusConflict) {
+			return nil, fmt.Errorf("Invoice %s: cannot be voided: %w", invoiceID, err)
+		}
+		return nil, err
+	}
+	if resp.IsEmpty() {
+		return f.GetInvoiceContext(ctx, invoiceID)
+	}
+
+	invoice := &Invoice{}
+	if err := f.decodeResponse(endpoint, resp, invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+type draftInvoice struct {
+	CustomerId int `json:"customerId"`
+}
+
+// CreateDraftInvoice creates an empty draft invoice for the customer, see AddInvoiceLineItem and IssueInvoice
+func (f *Fusebill) CreateDraftInvoice(customerID string) (*Invoice, error) {
+	return f.CreateDraftInvoiceContext(context.Background(), customerID)
+}
+
+// CreateDraftInvoiceContext creates an empty draft invoice for the customer using the ctx for cancellation.
+// The Id of the draft is what the follow-up calls take, a response without it is reported as a decode error.
+func (f *Fusebill) CreateDraftInvoiceContext(ctx context.Context, customerID string) (*Invoice, error) {
+	i, err := parseID("Customer", customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "/draftInvoices"
+	resp, err := f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: endpoint, Payload: &draftInvoice{CustomerId: i}, IdempotencyKey: key})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireContent(endpoint, resp); err != nil {
+		return nil, err
+	}
+
+	invoice := &Invoice{}
+	if err := f.decodeResponse(endpoint, resp, invoice); err != nil {
+		return nil, err
+	}
+	if invoice.Id == 0 {
+		return nil, decodeError(endpoint, resp.Body, errors.New("id is missing"))
+	}
+
+	return invoice, nil
+}
+
+// AddInvoiceLineItem adds the line item to the draft invoice
+func (f *Fusebill) AddInvoiceLineItem(invoiceID string, item LineItem) error {
+	return f.AddInvoiceLineItemContext(context.Background(), invoiceID, item)
+}
+
+// AddInvoiceLineItemContext adds the line item to the draft invoice using the ctx for cancellation.
+// An idempotency key is sent so a retried call never adds the item twice.
+func (f *Fusebill) AddInvoiceLineItemContext(ctx context.Context, invoiceID string, item LineItem) error {
+	if item.Quantity <= 0 {
+		return fmt.Errorf("Invoice %s: line item quantity is %.2f", invoiceID, item.Quantity)
+	}
+
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return err
+	}
+
+	_, err = f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/draftInvoices/" + invoiceID + "/lineItems", Payload: item, IdempotencyKey: key})
+	return err
+}
+
+// IssueInvoice finalizes the draft invoice so it is sent to the customer
+func (f *Fusebill) IssueInvoice(invoiceID string) error {
+	return f.IssueInvoiceContext(context.Background(), invoiceID)
+}
+
+// IssueInvoiceContext finalizes the draft invoice using the ctx for cancellation
+func (f *Fusebill) IssueInvoiceContext(ctx context.Context, invoiceID string) error {
+	_, err := f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/draftInvoices/" + invoiceID + "/issue"})
+	return err
+}
+
+// InvoicePage is a page of invoices returned by ListInvoicesPage and ListInvoicesPageContext
+type InvoicePage struct {
+	Invoices []Invoice
+	// HasMore is false once a page comes back with fewer invoices than requested
+	HasMore bool
+	// Next selects the following page
+	Next ListOptions
+	// PageInfo reports the total number of invoices when Fusebill provides it
+	PageInfo PageInfo
+}
+
+// ListInvoices returns a page of the customer's invoices
+func (f *Fusebill) ListInvoices(customerID string, opts ListOptions) ([]Invoice, error) {
+	return f.ListInvoicesContext(context.Background(), customerID, opts)
+}
+
+// ListInvoicesContext returns a page of the customer's invoices using the ctx for cancellation
+func (f *Fusebill) ListInvoicesContext(ctx context.Context, customerID string, opts ListOptions) ([]Invoice, error) {
+	page, err := f.ListInvoicesPageContext(ctx, customerID, opts)
+	return page.Invoices, err
+}
+
+// ListInvoicesPage returns a page of the customer's invoices along with whether more pages remain
+func (f *Fusebill) ListInvoicesPage(customerID string, opts ListOptions) (InvoicePage, error) {
+	return f.ListInvoicesPageContext(context.Background(), customerID, opts)
+}
+
+// ListInvoicesPageContext returns a page of the customer's invoices along with whether more pages remain,
+// using the ctx for cancellation
+func (f *Fusebill) ListInvoicesPageContext(ctx context.Context, customerID string, opts ListOptions) (InvoicePage, error) {
+	invoices := []Invoice{}
+	filter := NewQuery().Equals("customerId", customerID)
+	info, err := f.listPage(ctx, "/invoices", filter, opts, &invoices)
+	if err != nil {
+		return InvoicePage{}, err
+	}
+
+	return InvoicePage{Invoices: invoices, HasMore: opts.hasMore(len(invoices)), Next: opts.Next(), PageInfo: info}, nil
+}
+
+// ListCustomerInvoices returns all the customer's invoices dated within the range, e.g. for a monthly statement
+func (f *Fusebill) ListCustomerInvoices(customerID string, from, to time.Time) ([]Invoice, error) {
+	return f.ListCustomerInvoicesContext(context.Background(), customerID, from, to)
+}
+
+// ListCustomerInvoicesContext returns all the customer's invoices dated within the range using the ctx
+// for cancellation, fetching every page. The bounds are sent in the Location the client reads
+// Fusebill dates in; a zero bound leaves that side of the range open.
+func (f *Fusebill) ListCustomerInvoicesContext(ctx context.Context, customerID string, from, to time.Time) ([]Invoice, error) {
+	invoices := []Invoice{}
+	filter := NewQuery().Equals("customerId", customerID).Between("invoiceDate", from, to)
+	for opts := (ListOptions{}); ; opts = opts.Next() {
+		page := []Invoice{}
+		if err := f.list(ctx, "/invoices", filter, opts, &page); err != nil {
+			return nil, err
+		}
+
+		invoices = append(invoices, page...)
+		if !opts.hasMore(len(page)) {
+			return invoices, nil
+		}
+	}
+}
+
+// InvoiceIterator walks the customer's invoices fetching pages as needed
+type InvoiceIterator struct {
+	iterator
+	page []Invoice
+}
+
+// IterateInvoices returns an iterator over the customer's invoices starting at the page selected by opts
+func (f *Fusebill) IterateInvoices(customerID string, opts ListOptions) *InvoiceIterator {
+	return f.IterateInvoicesContext(context.Background(), customerID, opts)
+}
+
+// IterateInvoicesContext returns an iterator over the customer's invoices starting at the page
+// selected by opts, fetching the pages using the ctx for cancellation
+func (f *Fusebill) IterateInvoicesContext(ctx context.Context, customerID string, opts ListOptions) *InvoiceIterator {
+	it := &InvoiceIterator{}
+	it.ctx, it.opts = ctx, opts
+	it.fetch = func(ctx context.Context, opts ListOptions) (int, error) {
+		page, err := f.ListInvoicesPageContext(ctx, customerID, opts)
+		it.page = page.Invoices
+		return len(page.Invoices), err
+	}
+
+	return it
+}
+
+// Next advances to the next invoice, it returns false at the end or on error
+func (it *InvoiceIterator) Next() bool {
+	return it.next()
+}
+
+// Value returns the current invoice
+func (it *InvoiceIterator) Value() Invoice {
+	return it.page[it.pos]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *InvoiceIterator) Err() error {
+	return it.err
+}
+
+// GetInvoicePDF returns the rendered PDF of the invoice
+func (f *Fusebill) GetInvoicePDF(invoiceID string) ([]byte, error) {
+	return f.GetInvoicePDFContext(context.Background(), invoiceID)
+}
+
+// GetInvoicePDFContext returns the rendered PDF of the invoice using the ctx for cancellation.
+// A successful response that is not a PDF is reported as an error quoting its beginning.
+func (f *Fusebill) GetInvoicePDFContext(ctx context.Context, invoiceID string) ([]byte, error) {
+	endpoint := "/invoices/pdf/" + invoiceID
+	resp, err := f.SendRequestContext(ctx, RequestDetails{
+		Method:   "GET",
+		Endpoint: endpoint,
+		Accept:   "application/pdf",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ContentType != "application/pdf" {
+		return nil, decodeError(endpoint, resp.Body, fmt.Errorf("unexpected content type %q", resp.ContentType))
+	}
+
+	return resp.Body, nil
+}
+
+// OverdueOptions selects the invoices returned by ListOverdueInvoices
+type OverdueOptions struct {
+	// Statuses are the invoice statuses to include, "Sent" and "Overdue" when empty
+	Statuses []string
+	// DueBefore keeps the invoices due before it, now when zero
+	DueBefore time.Time
+}
+
+// ListOverdueInvoices returns all the unpaid invoices past due, sorted by due date ascending
+func (f *Fusebill) ListOverdueInvoices(opts OverdueOptions) ([]Invoice, error) {
+	return f.ListOverdueInvoicesContext(context.Background(), opts)
+}
+
+// ListOverdueInvoicesContext returns all the unpaid invoices past due using the ctx for cancellation.
+// Every page is fetched so the invoices can be sorted by due date.
+func (f *Fusebill) ListOverdueInvoicesContext(ctx context.Context, opts OverdueOptions) ([]Invoice, error) {
+	statuses := opts.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{"Sent", "Overdue"}
+	}
+
+	dueBefore := opts.DueBefore
+	if dueBefore.IsZero() {
+		dueBefore = time.Now()
+	}
+
+	invoices := []Invoice{}
+	for _, status := range statuses {
+		filter := NewQuery().Equals("status", status).Between("dueDate", time.Time{}, dueBefore)
+		for page := (ListOptions{}); ; page = page.Next() {
+			batch := []Invoice{}
+			if err := f.list(ctx, "/invoices", filter, page, &batch); err != nil {
+				return nil, err
+			}
+
+			invoices = append(invoices, batch...)
+			if !page.hasMore(len(batch)) {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(invoices, func(i, j int) bool {
+		return invoices[i].DueDate.Before(invoices[j].DueDate.Time)
+	})
+
+	return invoices, nil
+}
+
+type collection struct {
+	InvoiceId int `json:"invoiceId"`
+}
+
+// TriggerCollection attempts to collect the outstanding balance of the invoice through the Private API
+func (f *Fusebill) TriggerCollection(invoiceID string) error {
+	return f.TriggerCollectionContext(context.Background(), invoiceID)
+}
+
+// TriggerCollectionContext attempts to collect the outstanding balance of the invoice using the ctx for cancellation.
+// Like WriteOff it is only retried when the connection could not be established, so a collection is never charged twice.
+func (f *Fusebill) TriggerCollectionContext(ctx context.Context, invoiceID string) error {
+	i, err := parseID("Invoice", invoiceID)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.sendPrivate(ctx, RequestDetails{Method: "POST", Endpoint: "/api/invoices/collect", Payload: &collection{InvoiceId: i}})
+	return err
+}
+
+type invoiceEmail struct {
+	InvoiceId  int      `json:"invoiceId"`
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// EmailError is returned by SendInvoiceEmail when the invoice exists but could not be emailed
+type EmailError struct {
+	InvoiceId string
+	Err       error
+}
+
+func (e *EmailError) Error() string {
+	return fmt.Sprintf("Invoice %s: unable to send the email: %v", e.InvoiceId, e.Err)
+}
+
+func (e *EmailError) Unwrap() error {
+	return e.Err
+}
+
+// SendInvoiceEmail makes Fusebill email the invoice through the Private API, to the customer's
+// contacts or to the given recipients
+func (f *Fusebill) SendInvoiceEmail(invoiceID string, to []string) error {
+	return f.SendInvoiceEmailContext(context.Background(), invoiceID, to)
+}
+
+// SendInvoiceEmailContext makes Fusebill email the invoice using the ctx for cancellation.
+// An unknown invoice is reported as an error matching ErrNotFound, any other failure of
+// the email as *EmailError.
+func (f *Fusebill) SendInvoiceEmailContext(ctx context.Context, invoiceID string, to []string) error {
+	i, err := parseID("Invoice", invoiceID)
+	if err != nil {
+		return err
+	}
+	data := &invoiceEmail{InvoiceId: i, Recipients: to}
+
+	_, err = f.sendPrivate(ctx, RequestDetails{Method: "POST", Endpoint: "/api/invoices/email", Payload: data})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrUnauthorized) {
+		return &EmailError{InvoiceId: invoiceID, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,56 @@
+package fusebill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// pagedInvoices serves five invoices two per page
+func pagedInvoices(w http.ResponseWriter, r *http.Request) {
+	p, _ := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+	if p == 2 {
+		fmt.Fprint(w, `[{"id":5}]`)
+		return
+	}
+	fmt.Fprintf(w, `[{"id":%d},{"id":%d}]`, p*2+1, p*2+2)
+}
+
+func TestListInvoicesPage(t *testing.T) {
+	f, server := NewTestClient(http.HandlerFunc(pagedInvoices))
+	defer server.Close()
+
+	page, err := f.ListInvoicesPage("1", ListOptions{Limit: 2})
+	if err != nil || len(page.Invoices) != 2 || !page.HasMore || page.Next.Offset != 2 {
+		t.Fatalf("got %+v, %v", page, err)
+	}
+
+	page, err = f.ListInvoicesPageContext(context.Background(), "1", ListOptions{Offset: 4, Limit: 2})
+	if err != nil || len(page.Invoices) != 1 || page.HasMore {
+		t.Errorf("got %+v, %v, want the last page", page, err)
+	}
+}
+
+func TestIterateInvoices(t *testing.T) {
+	f, server := NewTestClient(http.HandlerFunc(pagedInvoices))
+	defer server.Close()
+
+	it := f.IterateInvoices("1", ListOptions{Limit: 2})
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Value().Id)
+	}
+	if it.Err() != nil || fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("got %v, %v, want the five invoices", ids, it.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = f.IterateInvoicesContext(ctx, "1", ListOptions{Limit: 2})
+	if it.Next() || !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", it.Err())
+	}
+}
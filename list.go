@@ -0,0 +1,197 @@
+package fusebill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultPageSize is the page size used when ListOptions.Limit is not set
+const DefaultPageSize = 100
+
+// ListOptions selects the page of a list endpoint. Fusebill pages by number, so
+// Offset should be a multiple of Limit.
+type ListOptions struct {
+	Offset int
+	Limit  int
+	// Query filters the results, in addition to any filter the list method applies itself
+	Query *QueryBuilder
+}
+
+func (o ListOptions) limit() int {
+	if o.Limit <= 0 {
+		return DefaultPageSize
+	}
+
+	return o.Limit
+}
+
+// Next returns the options selecting the following page
+func (o ListOptions) Next() ListOptions {
+	return ListOptions{Offset: o.Offset + o.limit(), Limit: o.limit(), Query: o.Query}
+}
+
+// values returns the paging parameters along with the filter merged with o.Query, its dates in loc
+func (o ListOptions) values(filter *QueryBuilder, loc *time.Location) url.Values {
+	v := filter.and(o.Query).build(loc)
+	v.Set("pageSize", strconv.Itoa(o.limit()))
+	v.Set("pageNumber", strconv.Itoa(o.Offset/o.limit()))
+	return v
+}
+
+// hasMore reports whether more pages may follow a page of n items, i.e. whether the page came back full
+func (o ListOptions) hasMore(n int) bool {
+	return n >= o.limit()
+}
+
+// PaginationHeader carries the paging metadata of a list response as a JSON object
+const PaginationHeader = "X-Pagination"
+
+// PageInfo describes the page returned by a list endpoint
+type PageInfo struct {
+	// TotalCount is the number of results across all pages, -1 when Fusebill did not report it
+	TotalCount int
+	Offset     int
+	Limit      int
+}
+
+// pageInfo returns the info of the page selected by opts, reading the total from the paging header
+func (o ListOptions) pageInfo(h http.Header) PageInfo {
+	info := PageInfo{TotalCount: -1, Offset: o.Offset, Limit: o.limit()}
+
+	pagination := &struct {
+		RecordCount *int `json:"recordCount"`
+	}{}
+	if json.Unmarshal([]byte(h.Get(PaginationHeader)), pagination) == nil && pagination.RecordCount != nil {
+		info.TotalCount = *pagination.RecordCount
+	}
+
+	return info
+}
+
+// list fetches a page of the list endpoint into out, which must point to a slice
+func (f *Fusebill) list(ctx context.Context, endpoint string, filter *QueryBuilder, opts ListOptions, out interface{}) error {
+	_, err := f.listPage(ctx, endpoint, filter, opts, out)
+	return err
+}
+
+// listPage fetches a page of the list endpoint into out like list and describes the page
+func (f *Fusebill) listPage(ctx context.Context, endpoint string, filter *QueryBuilder, opts ListOptions, out interface{}) (PageInfo, error) {
+	if err := filter.and(opts.Query).Err(); err != nil {
+		return PageInfo{}, err
+	}
+
+	values := opts.values(filter, f.location())
+	resp, err := f.SendRequestContext(ctx, RequestDetails{Method: "GET", Endpoint: endpoint + "?" + values.Encode()})
+	if err != nil {
+		return PageInfo{}, err
+	}
+
+	info := opts.pageInfo(resp.Headers)
+	if envelope := unwrapEnvelope(resp.Body); envelope != nil {
+		resp.Body = envelope.Data
+		envelope.Meta.apply(&info)
+	}
+
+	if err := f.decodeResponse(endpoint, resp, out); err != nil {
+		return PageInfo{}, err
+	}
+
+	return info, nil
+}
+
+// decodeList fetches the unpaged list endpoint into out, which must point to a slice,
+// unwrapping the envelope like listPage
+func (f *Fusebill) decodeList(ctx context.Context, endpoint string, out interface{}) error {
+	resp, err := f.SendRequestContext(ctx, RequestDetails{Method: "GET", Endpoint: endpoint})
+	if err != nil {
+		return err
+	}
+
+	if envelope := unwrapEnvelope(resp.Body); envelope != nil {
+		resp.Body = envelope.Data
+	}
+
+	return f.decodeResponse(endpoint, resp, out)
+}
+
+// listEnvelope is the {"data": [...], "meta": {...}} object some list responses are wrapped in
+type listEnvelope struct {
+	Data json.RawMessage `json:"data"`
+	Meta listMeta        `json:"meta"`
+}
+
+type listMeta struct {
+	TotalCount *int `json:"totalCount"`
+	Offset     *int `json:"offset"`
+	Limit      *int `json:"limit"`
+}
+
+// apply overrides the page info with the fields the meta object reports
+func (m listMeta) apply(info *PageInfo) {
+	if m.TotalCount != nil {
+		info.TotalCount = *m.TotalCount
+	}
+	if m.Offset != nil {
+		info.Offset = *m.Offset
+	}
+	if m.Limit != nil {
+		info.Limit = *m.Limit
+	}
+}
+
+// unwrapEnvelope returns the envelope of a wrapped list body, nil for a bare array
+func unwrapEnvelope(body []byte) *listEnvelope {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '{' {
+		return nil
+	}
+
+	envelope := &listEnvelope{}
+	if json.Unmarshal(body, envelope) != nil || envelope.Data == nil {
+		return nil
+	}
+
+	return envelope
+}
+
+// iterator walks a list endpoint page by page. fetch loads the page selected
+// by the options into the owner's buffer and returns the number of items in it.
+type iterator struct {
+	ctx     context.Context
+	opts    ListOptions
+	fetch   func(ctx context.Context, opts ListOptions) (int, error)
+	pos     int
+	n       int
+	more    bool
+	fetched bool
+	err     error
+}
+
+func (it *iterator) next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.pos++
+	for it.pos >= it.n {
+		if it.fetched && !it.more {
+			return false
+		}
+
+		n, err := it.fetch(it.ctx, it.opts)
+		it.fetched = true
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.pos, it.n, it.more, it.opts = 0, n, it.opts.hasMore(n), it.opts.Next()
+	}
+
+	return true
+}
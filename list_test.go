@@ -0,0 +1,66 @@
+package fusebill
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUnwrapEnvelope(t *testing.T) {
+	tests := []struct {
+		body     string
+		wantData string
+		wantNil  bool
+	}{
+		{`[{"id":1}]`, "", true},
+		{``, "", true},
+		{`{"id":1}`, "", true},
+		{`{"data":`, "", true},
+		{` {"data":[{"id":1}],"meta":{"totalCount":3}}`, `[{"id":1}]`, false},
+		{`{"data":[]}`, `[]`, false},
+	}
+
+	for _, tt := range tests {
+		envelope := unwrapEnvelope([]byte(tt.body))
+		if tt.wantNil {
+			if envelope != nil {
+				t.Errorf("unwrapEnvelope(%q) = %s, want nil", tt.body, envelope.Data)
+			}
+			continue
+		}
+		if envelope == nil || string(envelope.Data) != tt.wantData {
+			t.Errorf("unwrapEnvelope(%q) = %v, want data %s", tt.body, envelope, tt.wantData)
+		}
+	}
+}
+
+func TestListMeta(t *testing.T) {
+	envelope := unwrapEnvelope([]byte(`{"data":[],"meta":{"totalCount":42,"offset":10}}`))
+	info := PageInfo{TotalCount: -1, Offset: 0, Limit: 5}
+	envelope.Meta.apply(&info)
+
+	if info != (PageInfo{TotalCount: 42, Offset: 10, Limit: 5}) {
+		t.Errorf("got %+v", info)
+	}
+}
+
+func TestUnpagedListsUnwrapEnvelope(t *testing.T) {
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/customers/1/paymentMethods":
+			w.Write([]byte(`{"data":[{"id":7,"maskedNumber":"************4242"}],"meta":{}}`))
+		case "/subscriptions/1/upcomingCharges":
+			w.Write([]byte(`{"data":[{"amount":9.5}],"meta":{}}`))
+		}
+	}))
+	defer server.Close()
+
+	methods, err := f.ListPaymentMethods("1")
+	if err != nil || len(methods) != 1 || methods[0].Id != 7 || methods[0].LastFour() != "4242" {
+		t.Errorf("got %+v, %v", methods, err)
+	}
+
+	charges, err := f.GetUpcomingCharges("1")
+	if err != nil || len(charges) != 1 || charges[0].Amount != 9.5 {
+		t.Errorf("got %+v, %v", charges, err)
+	}
+}
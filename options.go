@@ -0,0 +1,193 @@
+package fusebill
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the HTTP client timeout used unless WithTimeout is given
+const DefaultTimeout = time.Second * 5
+
+// Option configures the client returned by NewClient and NewPrivateClient
+type Option func(*Fusebill)
+
+// WithTimeout sets the HTTP client timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(f *Fusebill) {
+		f.clientOptions = append(f.clientOptions, func(c *http.Client) {
+			c.Timeout = timeout
+		})
+	}
+}
+
+// WithHTTPClient makes the client send requests through a copy of the given *http.Client,
+// sharing its transport. Its Timeout takes precedence over DefaultTimeout. The options
+// configuring the HTTP client apply to the copy whatever their order, and neither they nor
+// the session cookie jar of NewPrivateClient change client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *Fusebill) {
+		f.httpClient = client
+	}
+}
+
+// WithEndpointTimeout bounds the calls to the endpoints starting with prefix, see Fusebill.EndpointTimeouts
+func WithEndpointTimeout(prefix string, timeout time.Duration) Option {
+	return func(f *Fusebill) {
+		if f.EndpointTimeouts == nil {
+			f.EndpointTimeouts = map[string]time.Duration{}
+		}
+		f.EndpointTimeouts[prefix] = timeout
+	}
+}
+
+// WithTLSClientCert presents the certificate for mutual TLS. It clones the transport of the
+// HTTP client, a transport that is not an *http.Transport cannot be configured and is
+// reported by Validate.
+func WithTLSClientCert(cert tls.Certificate) Option {
+	return func(f *Fusebill) {
+		f.transportOptions = append(f.transportOptions, func(t *http.Transport) {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.Certificates = append(t.TLSClientConfig.Certificates, cert)
+		})
+	}
+}
+
+// WithTransportConfig tunes the connection pool of the HTTP transport, e.g. for batch jobs
+// sending many concurrent requests. Like WithTLSClientCert it clones the transport.
+func WithTransportConfig(maxIdleConns, maxIdleConnsPerHost int, idleTimeout time.Duration) Option {
+	return func(f *Fusebill) {
+		f.transportOptions = append(f.transportOptions, func(t *http.Transport) {
+			t.MaxIdleConns = maxIdleConns
+			t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+			t.IdleConnTimeout = idleTimeout
+		})
+	}
+}
+
+// configureClient builds the HTTP client once every option ran, so their order does not
+// matter. It starts from a copy of the client given to WithHTTPClient, or a default one
+// with a transport of its own, and applies the options configuring the client. With
+// transport options it ends up with a configured clone of the transport, leaving the
+// transport of a client given to WithHTTPClient untouched.
+func (f *Fusebill) configureClient() {
+	client := &http.Client{Timeout: DefaultTimeout}
+	if f.httpClient != nil {
+		c := *f.httpClient
+		client = &c
+	} else {
+		f.transport = http.DefaultTransport.(*http.Transport).Clone()
+		client.Transport = f.transport
+	}
+
+	for _, configure := range f.clientOptions {
+		configure(client)
+	}
+	f.Client = client
+
+	if len(f.transportOptions) == 0 {
+		return
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t, ok := base.(*http.Transport)
+	if !ok {
+		f.optionErr = fmt.Errorf("transport %T cannot be configured, an *http.Transport is expected", base)
+		return
+	}
+
+	t = t.Clone()
+	for _, configure := range f.transportOptions {
+		configure(t)
+	}
+	client.Transport = t
+	f.transport = t
+}
+
+// WithBaseURL overrides the base url derived from the mode, e.g. for a regional endpoint or a local mock
+func WithBaseURL(baseURL string) Option {
+	return func(f *Fusebill) {
+		f.BaseUrl = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithAPIVersion targets another version of the API, e.g. "v2", instead of the default
+// /v1 of NewClient. NewPrivateClient has no version prefix unless one is given. It has
+// no effect along with WithBaseURL, whose url is used as is.
+func WithAPIVersion(version string) Option {
+	return func(f *Fusebill) {
+		f.apiVersion = strings.Trim(version, "/")
+	}
+}
+
+// WithUserAgent overrides DefaultUserAgent
+func WithUserAgent(userAgent string) Option {
+	return func(f *Fusebill) {
+		f.UserAgent = userAgent
+	}
+}
+
+// WithDefaultHeader adds a header sent with every request, e.g. X-Request-ID
+func WithDefaultHeader(key, value string) Option {
+	return func(f *Fusebill) {
+		if f.Headers == nil {
+			f.Headers = map[string]string{}
+		}
+		f.Headers[key] = value
+	}
+}
+
+// WithDryRun makes the client record mutating requests instead of sending them
+func WithDryRun() Option {
+	return func(f *Fusebill) {
+		f.DryRun = true
+	}
+}
+
+// WithLocation sets the time zone dates returned without an offset are read in, see Fusebill.Location
+func WithLocation(loc *time.Location) Option {
+	return func(f *Fusebill) {
+		f.Location = loc
+	}
+}
+
+// WithRetryableStatusCodes sets the status codes retried for idempotent requests, replacing
+// DefaultRetryableStatus. A 429 is always retried after its Retry-After delay.
+func WithRetryableStatusCodes(codes ...int) Option {
+	return func(f *Fusebill) {
+		f.Retry.RetryableStatus = append([]int(nil), codes...)
+	}
+}
+
+// WithStrictDecoding makes decoding fail on unknown response fields, see Fusebill.DisallowUnknownFields
+func WithStrictDecoding() Option {
+	return func(f *Fusebill) {
+		f.DisallowUnknownFields = true
+	}
+}
+
+// WithCheckRedirect sets the redirect policy of the HTTP client, see http.Client.CheckRedirect
+func WithCheckRedirect(checkRedirect func(req *http.Request, via []*http.Request) error) Option {
+	return func(f *Fusebill) {
+		f.clientOptions = append(f.clientOptions, func(c *http.Client) {
+			c.CheckRedirect = checkRedirect
+		})
+	}
+}
+
+// WithNoRedirect stops the HTTP client from following redirects. A private client
+// then treats a redirect, typically to the login page, as an expired session and
+// logs in again instead of reading the login form as the response.
+func WithNoRedirect() Option {
+	return WithCheckRedirect(func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+}
@@ -0,0 +1,52 @@
+package fusebill
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientOptionsInAnyOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func(hc *http.Client) []Option
+	}{
+		{"after WithHTTPClient", func(hc *http.Client) []Option {
+			return []Option{WithHTTPClient(hc), WithTimeout(30 * time.Second), WithNoRedirect(), WithTLSClientCert(tls.Certificate{})}
+		}},
+		{"before WithHTTPClient", func(hc *http.Client) []Option {
+			return []Option{WithTimeout(30 * time.Second), WithNoRedirect(), WithTLSClientCert(tls.Certificate{}), WithHTTPClient(hc)}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := &http.Client{Transport: &http.Transport{}}
+			f := NewPrivateClient(ModeStaging, Credentials{Username: "u", Password: "p"}, tt.opts(hc)...)
+
+			if f.Client == hc {
+				t.Fatal("the caller's client is used as is")
+			}
+			if f.Client.Timeout != 30*time.Second {
+				t.Errorf("got timeout %v, want 30s", f.Client.Timeout)
+			}
+			if f.Client.CheckRedirect == nil {
+				t.Error("redirect policy is lost")
+			}
+			transport, ok := f.Client.Transport.(*http.Transport)
+			if !ok || transport == hc.Transport || transport.TLSClientConfig == nil || len(transport.TLSClientConfig.Certificates) != 1 {
+				t.Error("client certificate is lost")
+			}
+			if f.Client.Jar == nil {
+				t.Error("session cookie jar is not set")
+			}
+
+			f.Close()
+			caller := hc.Transport.(*http.Transport).TLSClientConfig
+			if hc.Timeout != 0 || hc.CheckRedirect != nil || hc.Jar != nil || caller != nil && len(caller.Certificates) != 0 {
+				t.Error("the caller's client was changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package fusebill
+
+import (
+	"context"
+)
+
+type PaymentMethod struct {
+	Id         int `json:"id"`
+	CustomerId int `json:"customerId"`
+	// Type is "CreditCard" or "ACH"
+	Type string `json:"paymentMethodType"`
+	// MaskedNumber is the card or account number with all but the last digits masked
+	MaskedNumber string `json:"maskedNumber"`
+	IsDefault    bool   `json:"isDefault"`
+}
+
+// LastFour returns the last four characters of the masked number
+func (m PaymentMethod) LastFour() string {
+	if len(m.MaskedNumber) <= 4 {
+		return m.MaskedNumber
+	}
+
+	return m.MaskedNumber[len(m.MaskedNumber)-4:]
+}
+
+type PaymentMethodRequest struct {
+	// Type is "CreditCard" or "ACH"
+	Type string `json:"paymentMethodType"`
+	// Token is the card or bank account token issued by the payment gateway
+	Token     string `json:"token"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// ListPaymentMethods returns the payment methods of the customer
+func (f *Fusebill) ListPaymentMethods(customerID string) ([]PaymentMethod, error) {
+	return f.ListPaymentMethodsContext(context.Background(), customerID)
+}
+
+// ListPaymentMethodsContext returns the payment methods of the customer using the ctx for cancellation
+func (f *Fusebill) ListPaymentMethodsContext(ctx context.Context, customerID string) ([]PaymentMethod, error) {
+	endpoint := "/customers/" + customerID + "/paymentMethods"
+	methods := []PaymentMethod{}
+	if err := f.decodeList(ctx, endpoint, &methods); err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+// CreatePaymentMethod attaches a payment method to the customer
+func (f *Fusebill) CreatePaymentMethod(customerID string, req PaymentMethodRequest) (*PaymentMethod, error) {
+	return f.CreatePaymentMethodContext(context.Background(), customerID, req)
+}
+
+// CreatePaymentMethodContext attaches a payment method to the customer using the ctx for cancellation
+func (f *Fusebill) CreatePaymentMethodContext(ctx context.Context, customerID string, req PaymentMethodRequest) (*PaymentMethod, error) {
+	endpoint := "/customers/" + customerID + "/paymentMethods"
+	method := &PaymentMethod{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "POST", Endpoint: endpoint, Payload: req}, method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
@@ -0,0 +1,168 @@
+package fusebill
+
+import (
+	"context"
+	"fmt"
+)
+
+type Payment struct {
+	Id              int     `json:"id"`
+	Amount          float64 `json:"amount"`
+	PaymentDate     Date    `json:"paymentDate"`
+	Status          string  `json:"status"`
+	PaymentMethodId int     `json:"paymentMethodId"`
+}
+
+type InvoiceAllocation struct {
+	InvoiceId int     `json:"invoiceId"`
+	Amount    float64 `json:"amount"`
+}
+
+type PaymentRequest struct {
+	CustomerId         int                 `json:"customerId"`
+	Amount             float64             `json:"amount"`
+	PaymentMethodId    int                 `json:"paymentMethodId"`
+	InvoiceAllocations []InvoiceAllocation `json:"invoiceAllocations,omitempty"`
+}
+
+// GetPayment returns the payment, it maps to GET /payments/{id}
+func (f *Fusebill) GetPayment(paymentID string) (*Payment, error) {
+	return f.GetPaymentContext(context.Background(), paymentID)
+}
+
+// GetPaymentContext returns the payment using the ctx for cancellation
+func (f *Fusebill) GetPaymentContext(ctx context.Context, paymentID string) (*Payment, error) {
+	endpoint := "/payments/" + paymentID
+	payment := &Payment{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "GET", Endpoint: endpoint}, payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// ListPayments returns a page of the customer's payments, it maps to GET /customers/{id}/payments
+func (f *Fusebill) ListPayments(customerID string, opts ListOptions) ([]Payment, error) {
+	return f.ListPaymentsContext(context.Background(), customerID, opts)
+}
+
+// ListPaymentsContext returns a page of the customer's payments using the ctx for cancellation
+func (f *Fusebill) ListPaymentsContext(ctx context.Context, customerID string, opts ListOptions) ([]Payment, error) {
+	payments := []Payment{}
+	if err := f.list(ctx, "/customers/"+customerID+"/payments", nil, opts, &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// CreatePayment records the payment, it maps to POST /payments
+func (f *Fusebill) CreatePayment(req PaymentRequest) (*Payment, error) {
+	return f.CreatePaymentWithKeyContext(context.Background(), req, "")
+}
+
+// CreatePaymentWithKey records the payment sending the idempotency key
+func (f *Fusebill) CreatePaymentWithKey(req PaymentRequest, key string) (*Payment, error) {
+	return f.CreatePaymentWithKeyContext(context.Background(), req, key)
+}
+
+// CreatePaymentWithKeyContext records the payment sending the idempotency key, using the ctx for cancellation.
+// A random key is generated when key is empty, so retries of this call are never applied twice.
+// Validation failures are returned as *APIError carrying the server's message.
+func (f *Fusebill) CreatePaymentWithKeyContext(ctx context.Context, req PaymentRequest, key string) (*Payment, error) {
+	if key == "" {
+		var err error
+		if key, err = newIdempotencyKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := "/payments"
+	payment := &Payment{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "POST", Endpoint: endpoint, Payload: req, IdempotencyKey: key}, payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+type paymentApplication struct {
+	PaymentId          int                 `json:"paymentId"`
+	InvoiceAllocations []InvoiceAllocation `json:"invoiceAllocations"`
+}
+
+// ApplyPayment allocates an unapplied payment across the invoices
+func (f *Fusebill) ApplyPayment(paymentID string, allocations []InvoiceAllocation) error {
+	return f.ApplyPaymentContext(context.Background(), paymentID, allocations)
+}
+
+// ApplyPaymentContext allocates an unapplied payment across the invoices using the ctx for cancellation.
+// An idempotency key is sent so retries are never applied twice, rejections are returned as *APIError.
+func (f *Fusebill) ApplyPaymentContext(ctx context.Context, paymentID string, allocations []InvoiceAllocation) error {
+	i, err := parseID("Payment", paymentID)
+	if err != nil {
+		return err
+	}
+	if len(allocations) == 0 {
+		return fmt.Errorf("Payment %s: no invoice allocations", paymentID)
+	}
+	for _, a := range allocations {
+		if a.Amount <= 0 {
+			return fmt.Errorf("Payment %s: amount allocated to invoice %d is %.2f", paymentID, a.InvoiceId, a.Amount)
+		}
+	}
+
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return err
+	}
+
+	data := &paymentApplication{PaymentId: i, InvoiceAllocations: allocations}
+
+	_, err = f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/paymentAllocations", Payload: data, IdempotencyKey: key})
+	return err
+}
+
+type Refund struct {
+	PaymentId int     `json:"paymentId"`
+	Amount    float64 `json:"amount"`
+}
+
+// Refund refunds the amount of the payment through the Private API and returns the Id of the created refund
+func (f *Fusebill) Refund(paymentID string, amount float64) (int, error) {
+	return f.RefundContext(context.Background(), paymentID, amount)
+}
+
+// RefundContext refunds the amount of the payment using the ctx for cancellation.
+// Like WriteOff it is only retried when the connection could not be established.
+func (f *Fusebill) RefundContext(ctx context.Context, paymentID string, amount float64) (int, error) {
+	if amount <= 0 {
+		return 0, fmt.Errorf("Payment %s: refund amount is %.2f", paymentID, amount)
+	}
+
+	i, err := parseID("Payment", paymentID)
+	if err != nil {
+		return 0, err
+	}
+	data := &Refund{PaymentId: i, Amount: amount}
+
+	endpoint := "/api/payments/refund"
+	resp, err := f.sendPrivate(ctx, RequestDetails{Method: "POST", Endpoint: endpoint, Payload: data})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := requireContent(endpoint, resp); err != nil {
+		return 0, err
+	}
+
+	refund := &struct {
+		Id int `json:"id"`
+	}{}
+	// only the id is read, so the rest of the refund is never rejected as unknown
+	if err := decodeBody(endpoint, resp, refund, false); err != nil {
+		return 0, err
+	}
+
+	return refund.Id, nil
+}
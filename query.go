@@ -0,0 +1,134 @@
+package fusebill
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// QueryTimeFormat is the layout of dates in queries. They are sent in the Location of the
+// client, the one Fusebill dates are read in, and in UTC by String and Build.
+const QueryTimeFormat = "2006-01-02T15:04:05"
+
+// ErrInvalidQuery is wrapped in the error of a query with a value holding the ; separator
+var ErrInvalidQuery = errors.New("fusebill: invalid query")
+
+// QueryBuilder builds the query parameter used by Fusebill list endpoints to filter
+// results, e.g. query=customerId:123;status:Active. The zero value is an empty query.
+type QueryBuilder struct {
+	terms []queryTerm
+	err   error
+}
+
+// queryTerm is a field:value filter, or a field:from|to range whose bounds are
+// formatted once the location is known
+type queryTerm struct {
+	field    string
+	value    string
+	isRange  bool
+	from, to time.Time
+}
+
+func (t queryTerm) format(loc *time.Location) string {
+	if !t.isRange {
+		return t.field + ":" + t.value
+	}
+
+	return t.field + ":" + formatQueryTime(t.from, loc) + "|" + formatQueryTime(t.to, loc)
+}
+
+// NewQuery returns an empty query
+func NewQuery() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Equals adds a filter matching field against value. Fusebill splits a term on its first
+// colon, so the value may hold colons, but it cannot escape the ; separating the terms.
+// A value holding one is left out and reported by Err.
+func (q *QueryBuilder) Equals(field, value string) *QueryBuilder {
+	if strings.Contains(value, ";") {
+		if q.err == nil {
+			q.err = fmt.Errorf("%w: value %q of %s contains ;", ErrInvalidQuery, value, field)
+		}
+		return q
+	}
+
+	q.terms = append(q.terms, queryTerm{field: field, value: value})
+	return q
+}
+
+// Between adds a filter matching field against the time range, as field:from|to.
+// A zero bound leaves that side of the range open.
+func (q *QueryBuilder) Between(field string, from, to time.Time) *QueryBuilder {
+	q.terms = append(q.terms, queryTerm{field: field, isRange: true, from: from, to: to})
+	return q
+}
+
+// Err returns the error of the first rejected filter, list methods fail with it
+func (q *QueryBuilder) Err() error {
+	if q == nil {
+		return nil
+	}
+
+	return q.err
+}
+
+func formatQueryTime(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.In(loc).Format(QueryTimeFormat)
+}
+
+// String returns the unescaped query
+func (q *QueryBuilder) String() string {
+	return q.format(time.UTC)
+}
+
+func (q *QueryBuilder) format(loc *time.Location) string {
+	if q == nil {
+		return ""
+	}
+
+	terms := make([]string, len(q.terms))
+	for i, t := range q.terms {
+		terms[i] = t.format(loc)
+	}
+
+	return strings.Join(terms, ";")
+}
+
+// Build returns the query as url values, they are percent-encoded by Encode so
+// characters such as + and @ in emails survive the round trip
+func (q *QueryBuilder) Build() url.Values {
+	return q.build(time.UTC)
+}
+
+// build returns the query as url values with its dates in loc
+func (q *QueryBuilder) build(loc *time.Location) url.Values {
+	v := url.Values{}
+	if s := q.format(loc); s != "" {
+		v.Set("query", s)
+	}
+
+	return v
+}
+
+// and returns a new query holding the filters of both queries
+func (q *QueryBuilder) and(other *QueryBuilder) *QueryBuilder {
+	merged := &QueryBuilder{err: q.Err()}
+	if merged.err == nil {
+		merged.err = other.Err()
+	}
+	if q != nil {
+		merged.terms = append(merged.terms, q.terms...)
+	}
+	if other != nil {
+		merged.terms = append(merged.terms, other.terms...)
+	}
+
+	return merged
+}
@@ -0,0 +1,87 @@
+package fusebill
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		query   *QueryBuilder
+		want    string
+		encoded string
+	}{
+		{"empty", NewQuery(), "", ""},
+		{"nil", nil, "", ""},
+		{"terms", NewQuery().Equals("customerId", "123").Equals("status", "Active"),
+			"customerId:123;status:Active", "query=customerId%3A123%3Bstatus%3AActive"},
+		{"email", NewQuery().Equals("primaryEmail", "a+b@example.com"),
+			"primaryEmail:a+b@example.com", "query=primaryEmail%3Aa%2Bb%40example.com"},
+		{"colon in value", NewQuery().Equals("reference", "po:42"), "reference:po:42", "query=reference%3Apo%3A42"},
+		{"range", NewQuery().Between("invoiceDate", from, to),
+			"invoiceDate:2026-01-01T00:00:00|2026-02-01T12:30:00", "query=invoiceDate%3A2026-01-01T00%3A00%3A00%7C2026-02-01T12%3A30%3A00"},
+		{"open range", NewQuery().Between("dueDate", time.Time{}, to),
+			"dueDate:|2026-02-01T12:30:00", "query=dueDate%3A%7C2026-02-01T12%3A30%3A00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := tt.query.Build().Encode(); got != tt.encoded {
+				t.Errorf("Build().Encode() = %q, want %q", got, tt.encoded)
+			}
+			if err := tt.query.Err(); err != nil {
+				t.Errorf("Err() = %v", err)
+			}
+		})
+	}
+}
+
+func TestQuerySeparatorInValue(t *testing.T) {
+	q := NewQuery().Equals("status", "Active;customerId:1").Equals("customerId", "2")
+	if !errors.Is(q.Err(), ErrInvalidQuery) {
+		t.Errorf("Err() = %v, want ErrInvalidQuery", q.Err())
+	}
+	if got := q.String(); got != "customerId:2" {
+		t.Errorf("String() = %q, want the rejected term left out", got)
+	}
+
+	requests := 0
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	if _, err := f.ListCustomers(CustomerListOptions{Status: "Active;x"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("got %v, want ErrInvalidQuery", err)
+	}
+	if requests != 0 {
+		t.Errorf("sent %d requests, want none", requests)
+	}
+}
+
+func TestQueryDatesInLocation(t *testing.T) {
+	var query string
+	f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	f.Location = time.FixedZone("EST", -5*3600)
+
+	from := time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC)
+	if _, err := f.ListCustomerInvoices("1", from, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "customerId:1;invoiceDate:2026-01-01T00:00:00|"; query != want {
+		t.Errorf("sent query %q, want %q", query, want)
+	}
+}
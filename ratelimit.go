@@ -0,0 +1,66 @@
+package fusebill
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit throttles the requests sent by the client. The zero value means unlimited.
+type RateLimit struct {
+	RequestsPerSecond float64
+	// Burst is how many requests can be sent at once after a quiet period, at least 1
+	Burst int
+}
+
+// tokenBucket holds the state of the RateLimit of a client
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// wait blocks until the rate limit allows another request or the ctx is done
+func (f *Fusebill) wait(ctx context.Context) error {
+	limit := f.RateLimit
+	if limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	b := &f.bucket
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		if b.last.IsZero() {
+			b.tokens = burst
+		} else {
+			b.tokens += now.Sub(b.last).Seconds() * limit.RequestsPerSecond
+			if b.tokens > burst {
+				b.tokens = burst
+			}
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		delay := time.Duration((1 - b.tokens) / limit.RequestsPerSecond * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package fusebill
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// AgingOptions selects the accounts receivable aging report returned by GetAgingReport
+type AgingOptions struct {
+	// AsOf is the date the invoices are aged at, today when zero
+	AsOf time.Time
+	// CustomerId restricts the report to one customer, all customers when empty
+	CustomerId string
+}
+
+// AgingReport is the accounts receivable aging report. Each row holds the outstanding
+// balances of a customer's invoices bucketed by the whole days between their due date
+// and AsOf: Current is not yet past due, Days30 is 1 to 30 days past due, Days60 31 to 60,
+// Days90 61 to 90 and Days90Plus more than 90 days past due.
+type AgingReport struct {
+	AsOf time.Time  `json:"-"`
+	Rows []AgingRow `json:"rows"`
+}
+
+// AgingRow is the aged outstanding balance of one customer
+type AgingRow struct {
+	CustomerId int     `json:"customerId"`
+	Current    float64 `json:"current"`
+	Days30     float64 `json:"days30"`
+	Days60     float64 `json:"days60"`
+	Days90     float64 `json:"days90"`
+	Days90Plus float64 `json:"days90Plus"`
+	Total      float64 `json:"total"`
+}
+
+// GetAgingReport returns the accounts receivable aging report
+func (f *Fusebill) GetAgingReport(opts AgingOptions) (*AgingReport, error) {
+	return f.GetAgingReportContext(context.Background(), opts)
+}
+
+// GetAgingReportContext returns the accounts receivable aging report using the ctx for cancellation.
+// AsOf is sent as a calendar date in its own location, so the buckets follow that day.
+func (f *Fusebill) GetAgingReportContext(ctx context.Context, opts AgingOptions) (*AgingReport, error) {
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	values := url.Values{}
+	values.Set("asOf", asOf.Format("2006-01-02"))
+	if opts.CustomerId != "" {
+		values.Set("customerId", opts.CustomerId)
+	}
+
+	report := &AgingReport{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "GET", Endpoint: "/reports/arAging?" + values.Encode()}, report); err != nil {
+		return nil, err
+	}
+	if report.Rows == nil {
+		report.Rows = []AgingRow{}
+	}
+
+	report.AsOf = asOf
+	return report, nil
+}
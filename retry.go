@@ -0,0 +1,158 @@
+package fusebill
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// DefaultRetryableStatus is used when RetryConfig.RetryableStatus is empty
+var DefaultRetryableStatus = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// RetryConfig describes how failed requests are retried. The zero value disables retries.
+type RetryConfig struct {
+	MaxRetries      int
+	BaseDelay       time.Duration
+	RetryableStatus []int
+}
+
+func (c RetryConfig) isRetryableStatus(code int) bool {
+	statuses := c.RetryableStatus
+	if len(statuses) == 0 {
+		statuses = DefaultRetryableStatus
+	}
+
+	for _, s := range statuses {
+		if s == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maxRetryDelay caps the exponential backoff between retries
+const maxRetryDelay = time.Minute
+
+// backoff returns the exponential delay with jitter before the given retry attempt
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	if c.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := c.BaseDelay
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses the Retry-After header given either in seconds or as an HTTP-date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+
+	return 0, true
+}
+
+// isIdempotent reports whether a request with the method can be repeated safely
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPatch:
+		return false
+	}
+
+	return true
+}
+
+// isConnectionError reports whether err proves the request never reached the server, i.e. the
+// connection could not be established. A reset or EOF may come after the server processed the
+// request, so they are not included.
+func isConnectionError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// do sends the request built by newRequest through client, retrying according to f.Retry.
+// Retryable statuses are only retried for idempotent requests, otherwise
+// only errors establishing the connection are retried. A 429 is always retried since the
+// request was not processed, waiting as long as its Retry-After header asks. The body of
+// a stream response is left unread, see roundTrip.
+func (f *Fusebill) do(ctx context.Context, client *http.Client, newRequest func() (*http.Request, error), idempotent, stream bool) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := f.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		request, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := f.roundTrip(client, request, stream)
+
+		retry := false
+		delay := f.Retry.backoff(attempt)
+		if attempt < f.Retry.MaxRetries {
+			if err != nil {
+				retry = ctx.Err() == nil && (idempotent || isConnectionError(err))
+			} else if resp.StatusCode == http.StatusTooManyRequests {
+				retry = true
+				if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+					delay = after
+				}
+			} else {
+				retry = idempotent && f.Retry.isRetryableStatus(resp.StatusCode)
+			}
+		}
+
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package fusebill
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&net.OpError{Op: "dial", Err: errors.New("no route to host")}, true},
+		{&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNREFUSED)}, true},
+		{&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, false},
+		{fmt.Errorf("post: %w", io.EOF), false},
+		{io.ErrUnexpectedEOF, false},
+	}
+
+	for _, tt := range tests {
+		if got := isConnectionError(tt.err); got != tt.want {
+			t.Errorf("isConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// dropConnection closes the connection once the request has been read, as a server crashing mid-request would
+func dropConnection(w http.ResponseWriter, r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  RequestDetails
+		failures int32
+		fail     func(w http.ResponseWriter, r *http.Request)
+		want     int32
+		wantErr  bool
+	}{
+		{
+			name:     "GET on unavailable",
+			request:  RequestDetails{Method: "GET", Endpoint: "/customers/1"},
+			failures: 2,
+			fail:     func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+			want:     3,
+		},
+		{
+			name:     "GET on dropped connection",
+			request:  RequestDetails{Method: "GET", Endpoint: "/customers/1"},
+			failures: 1,
+			fail:     dropConnection,
+			want:     2,
+		},
+		{
+			name:     "POST on unavailable",
+			request:  RequestDetails{Method: "POST", Endpoint: "/payments", Payload: map[string]int{"amount": 5}},
+			failures: 1,
+			fail:     func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+			want:     1,
+			wantErr:  true,
+		},
+		{
+			name:     "POST on dropped connection",
+			request:  RequestDetails{Method: "POST", Endpoint: "/payments", Payload: map[string]int{"amount": 5}},
+			failures: 1,
+			fail:     dropConnection,
+			want:     1,
+			wantErr:  true,
+		},
+		{
+			name:     "POST with idempotency key on dropped connection",
+			request:  RequestDetails{Method: "POST", Endpoint: "/payments", Payload: map[string]int{"amount": 5}, IdempotencyKey: "key"},
+			failures: 1,
+			fail:     dropConnection,
+			want:     2,
+		},
+		{
+			name:     "POST on too many requests",
+			request:  RequestDetails{Method: "POST", Endpoint: "/payments", Payload: map[string]int{"amount": 5}},
+			failures: 1,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			f, server := NewTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= tt.failures {
+					tt.fail(w, r)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+			f.Retry = RetryConfig{MaxRetries: 3}
+
+			_, err := f.SendRequest(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if n := atomic.LoadInt32(&requests); n != tt.want {
+				t.Errorf("sent %d requests, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPOSTWhenConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	var attempts int32
+	f := NewClient(ModeStaging, Credentials{Token: TestToken}, WithBaseURL("http://"+addr))
+	f.Retry = RetryConfig{MaxRetries: 2}
+	f.RequestModifier = func(r *http.Request) error {
+		atomic.AddInt32(&attempts, 1)
+		return nil
+	}
+
+	if _, err := f.SendRequest(RequestDetails{Method: "POST", Endpoint: "/payments", Payload: map[string]int{"amount": 5}}); err == nil {
+		t.Fatal("request to a closed port succeeded")
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("made %d attempts, want 3", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Fri, 02 Jan 2026 10:00:30 GMT", 30 * time.Second, true},
+		{"Fri, 02 Jan 2026 09:59:00 GMT", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
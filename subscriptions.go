@@ -0,0 +1,120 @@
+package fusebill
+
+import (
+	"context"
+	"time"
+)
+
+type Subscription struct {
+	Id             int    `json:"id"`
+	CustomerId     int    `json:"customerId"`
+	Status         string `json:"status"`
+	ActivationDate string `json:"activationTimestamp"`
+	PlanCode       string `json:"planCode"`
+	PlanName       string `json:"planName"`
+}
+
+// CancelOptions controls how CancelSubscription cancels a subscription
+type CancelOptions struct {
+	// EffectiveDate is when the cancellation takes effect, immediately when zero
+	EffectiveDate time.Time
+	// Prorate credits the customer for the unused part of the current period
+	Prorate bool
+}
+
+type subscriptionCancellation struct {
+	SubscriptionId     int        `json:"subscriptionId"`
+	EffectiveTimestamp *time.Time `json:"effectiveTimestamp,omitempty"`
+	Prorate            bool       `json:"prorate"`
+}
+
+// GetSubscription returns the subscription
+func (f *Fusebill) GetSubscription(id string) (*Subscription, error) {
+	return f.GetSubscriptionContext(context.Background(), id)
+}
+
+// GetSubscriptionContext returns the subscription using the ctx for cancellation
+func (f *Fusebill) GetSubscriptionContext(ctx context.Context, id string) (*Subscription, error) {
+	endpoint := "/subscriptions/" + id
+	subscription := &Subscription{}
+	if err := f.decodeValue(ctx, RequestDetails{Method: "GET", Endpoint: endpoint}, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// ActivateSubscription activates the subscription
+func (f *Fusebill) ActivateSubscription(id string) error {
+	return f.ActivateSubscriptionContext(context.Background(), id)
+}
+
+// ActivateSubscriptionContext activates the subscription using the ctx for cancellation
+func (f *Fusebill) ActivateSubscriptionContext(ctx context.Context, id string) error {
+	_, err := f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/subscriptionActivation/" + id})
+	return err
+}
+
+// CancelSubscription cancels the subscription
+func (f *Fusebill) CancelSubscription(id string, opts CancelOptions) error {
+	return f.CancelSubscriptionContext(context.Background(), id, opts)
+}
+
+// CancelSubscriptionContext cancels the subscription using the ctx for cancellation
+func (f *Fusebill) CancelSubscriptionContext(ctx context.Context, id string, opts CancelOptions) error {
+	i, err := parseID("Subscription", id)
+	if err != nil {
+		return err
+	}
+	data := &subscriptionCancellation{SubscriptionId: i, Prorate: opts.Prorate}
+	if !opts.EffectiveDate.IsZero() {
+		data.EffectiveTimestamp = &opts.EffectiveDate
+	}
+
+	_, err = f.SendRequestContext(ctx, RequestDetails{Method: "POST", Endpoint: "/subscriptionCancellation", Payload: data})
+	return err
+}
+
+// ListSubscriptions returns all the subscriptions of the customer
+func (f *Fusebill) ListSubscriptions(customerID string) ([]Subscription, error) {
+	return f.ListSubscriptionsContext(context.Background(), customerID)
+}
+
+// ListSubscriptionsContext returns all the subscriptions of the customer using the ctx for cancellation, fetching every page
+func (f *Fusebill) ListSubscriptionsContext(ctx context.Context, customerID string) ([]Subscription, error) {
+	subscriptions := []Subscription{}
+	for opts := (ListOptions{}); ; opts = opts.Next() {
+		page := []Subscription{}
+		if err := f.list(ctx, "/customers/"+customerID+"/subscriptions", nil, opts, &page); err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, page...)
+		if !opts.hasMore(len(page)) {
+			return subscriptions, nil
+		}
+	}
+}
+
+// Charge is an upcoming charge of a subscription
+type Charge struct {
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	ChargeDate  string  `json:"chargeDate"`
+}
+
+// GetUpcomingCharges returns the next charges of the subscription
+func (f *Fusebill) GetUpcomingCharges(subscriptionID string) ([]Charge, error) {
+	return f.GetUpcomingChargesContext(context.Background(), subscriptionID)
+}
+
+// GetUpcomingChargesContext returns the next charges of the subscription using the ctx for cancellation,
+// as previewed by Fusebill for the coming renewal
+func (f *Fusebill) GetUpcomingChargesContext(ctx context.Context, subscriptionID string) ([]Charge, error) {
+	charges := []Charge{}
+	if err := f.decodeList(ctx, "/subscriptions/"+subscriptionID+"/upcomingCharges", &charges); err != nil {
+		return nil, err
+	}
+
+	return charges, nil
+}
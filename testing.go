@@ -0,0 +1,36 @@
+package fusebill
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+)
+
+// TestToken is the token sent by clients returned from NewTestClient and NewTestPrivateClient
+const TestToken = "test-token"
+
+// NewTestClient returns a public API client talking to a httptest.Server that serves handler.
+// The client sends TestToken, private API methods fail as on a client from NewClient.
+// The caller should Close the server when done.
+func NewTestClient(handler http.Handler) (*Fusebill, *httptest.Server) {
+	server := httptest.NewServer(handler)
+
+	client := *server.Client()
+	return &Fusebill{
+		BaseUrl:     server.URL,
+		Credentials: Credentials{Username: "test", Password: "test", Token: TestToken},
+		Client:      &client,
+	}, server
+}
+
+// NewTestPrivateClient returns a private API client talking to a httptest.Server that
+// serves handler. Like a client from NewPrivateClient it logs in with the test credentials
+// and keeps the session cookie. The caller should Close the server when done.
+func NewTestPrivateClient(handler http.Handler) (*Fusebill, *httptest.Server) {
+	client, server := NewTestClient(handler)
+
+	client.cookieJar, _ = cookiejar.New(nil)
+	client.Client.Jar = client.cookieJar
+
+	return client, server
+}